@@ -0,0 +1,346 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy 表示异步输出队列已满时的处理方式。
+type OverflowPolicy string
+
+const (
+	// OverflowBlock 阻塞写入方，直到队列有空位，不丢弃任何日志。
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest 丢弃正在写入的日志。
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest 丢弃队列中最早的日志，为正在写入的日志腾出空位。
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowDropBelowLevel 丢弃低于 AsyncConfig.DropLevel 的日志，其余日志阻塞等待。
+	OverflowDropBelowLevel OverflowPolicy = "drop_below_level"
+)
+
+/*
+AsyncConfig 描述异步输出的缓冲策略。日志在调用方 goroutine 中完成编码后进入有界队列，
+由后台 goroutine 攒批写入；Sync 与 Close 会等待队列中的日志全部写出。
+
+属性说明：
+  - QueueSize：队列容量（条），不大于0时使用1024。
+  - BatchSize：队列中积累到该条数时立即写出，不大于0时使用128。
+  - FlushInterval：即使未攒满一批，也至少按该间隔写出一次，不大于0时使用1秒。
+  - Overflow：队列已满时的处理方式，为空时使用 OverflowBlock。
+  - DropLevel：仅 OverflowDropBelowLevel 使用，为空时使用 "warn"。
+*/
+type AsyncConfig struct {
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	Overflow      OverflowPolicy
+	DropLevel     string
+}
+
+// DefaultAsyncConfig 返回默认的异步输出策略：队列1024条，每128条或每秒写出一次，队列满时阻塞。
+func DefaultAsyncConfig() AsyncConfig {
+	return AsyncConfig{
+		QueueSize:     1024,
+		BatchSize:     128,
+		FlushInterval: time.Second,
+		Overflow:      OverflowBlock,
+		DropLevel:     "warn",
+	}
+}
+
+// withDefaults 用默认值补全未设置的项。
+func (cfg AsyncConfig) withDefaults() AsyncConfig {
+	def := DefaultAsyncConfig()
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = def.QueueSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.BatchSize > cfg.QueueSize {
+		cfg.BatchSize = cfg.QueueSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = def.Overflow
+	}
+	if cfg.DropLevel == "" {
+		cfg.DropLevel = def.DropLevel
+	}
+	return cfg
+}
+
+// validate 检查溢出策略与丢弃级别是否合法。
+func (cfg AsyncConfig) validate() error {
+	switch cfg.Overflow {
+	case "", OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowDropBelowLevel:
+	default:
+		return fmt.Errorf("logger: unknown overflow policy %q", cfg.Overflow)
+	}
+	return validateLevel(cfg.DropLevel)
+}
+
+// asyncItem 是队列中一条已编码的日志。
+type asyncItem struct {
+	level zapcore.Level
+	data  []byte
+}
+
+// asyncQueue 是异步输出的有界环形队列及其后台写入 goroutine。
+type asyncQueue struct {
+	out       zapcore.WriteSyncer
+	cfg       AsyncConfig
+	dropLevel zapcore.Level
+	reportEnc zapcore.Encoder // 用于输出丢弃统计的编码器。
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	items   []asyncItem
+	head    int
+	count   int
+	closed  bool
+
+	writeMu  sync.Mutex    // 保证批次按入队顺序写出。
+	dropped  atomic.Uint64 // 累计丢弃的条数。
+	reported uint64        // 已经输出过统计的丢弃条数，受 writeMu 保护。
+
+	signal chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// newAsyncQueue 创建异步队列并启动后台写入 goroutine。
+func newAsyncQueue(out zapcore.WriteSyncer, enc zapcore.Encoder, cfg AsyncConfig) *asyncQueue {
+	cfg = cfg.withDefaults()
+	q := &asyncQueue{
+		out:       out,
+		cfg:       cfg,
+		dropLevel: GetLogLevel(cfg.DropLevel),
+		reportEnc: enc.Clone(),
+		items:     make([]asyncItem, cfg.QueueSize),
+		signal:    make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	q.notFull = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// push 将一条日志放入队列，队列已满时按溢出策略处理；队列关闭后直接同步写出。
+func (q *asyncQueue) push(level zapcore.Level, data []byte) error {
+	q.mu.Lock()
+	for q.count == len(q.items) && !q.closed {
+		switch {
+		case q.cfg.Overflow == OverflowDropNewest,
+			q.cfg.Overflow == OverflowDropBelowLevel && level < q.dropLevel:
+			q.mu.Unlock()
+			q.dropped.Add(1)
+			return nil
+		case q.cfg.Overflow == OverflowDropOldest:
+			q.items[q.head] = asyncItem{}
+			q.head = (q.head + 1) % len(q.items)
+			q.count--
+			q.dropped.Add(1)
+		default:
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		q.mu.Unlock()
+		q.writeMu.Lock()
+		defer q.writeMu.Unlock()
+		_, err := q.out.Write(data)
+		return err
+	}
+
+	q.items[(q.head+q.count)%len(q.items)] = asyncItem{level: level, data: data}
+	q.count++
+	full := q.count >= q.cfg.BatchSize
+	q.mu.Unlock()
+
+	if full {
+		select {
+		case q.signal <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// run 在攒满一批、到达写出间隔或收到停止信号时写出队列中的日志。
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	ticker := time.NewTicker(q.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.signal:
+		case <-ticker.C:
+		}
+		_ = q.drain()
+	}
+}
+
+// drain 将队列中的日志全部写出，并输出新增的丢弃统计。
+func (q *asyncQueue) drain() error {
+	q.writeMu.Lock()
+	defer q.writeMu.Unlock()
+	return q.drainLocked()
+}
+
+// drainLocked 与 drain 相同，调用方需持有 writeMu。
+func (q *asyncQueue) drainLocked() error {
+	var errs error
+	for {
+		q.mu.Lock()
+		n := q.count
+		if n > q.cfg.BatchSize {
+			n = q.cfg.BatchSize
+		}
+		batch := make([]byte, 0, n*128)
+		for i := 0; i < n; i++ {
+			idx := (q.head + i) % len(q.items)
+			batch = append(batch, q.items[idx].data...)
+			q.items[idx] = asyncItem{}
+		}
+		q.head = (q.head + n) % len(q.items)
+		q.count -= n
+		q.notFull.Broadcast()
+		q.mu.Unlock()
+
+		if n == 0 {
+			break
+		}
+		if _, err := q.out.Write(batch); err != nil {
+			errs = err
+		}
+	}
+	q.reportDropped()
+	return errs
+}
+
+// reportDropped 在出现新的丢弃时输出一条警告日志，调用方需持有 writeMu。
+func (q *asyncQueue) reportDropped() {
+	total := q.dropped.Load()
+	if total == q.reported {
+		return
+	}
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: "[AsyncSink] dropped log entries"}
+	buf, err := q.reportEnc.EncodeEntry(ent, []zapcore.Field{
+		zap.Uint64("dropped", total-q.reported),
+		zap.Uint64("total", total),
+	})
+	if err != nil {
+		return
+	}
+	_, _ = q.out.Write(buf.Bytes())
+	buf.Free()
+	q.reported = total
+}
+
+// Dropped 返回累计丢弃的日志条数。
+func (q *asyncQueue) Dropped() uint64 {
+	return q.dropped.Load()
+}
+
+// Sync 写出队列中的全部日志并刷新底层输出。
+func (q *asyncQueue) Sync() error {
+	err := q.drain()
+	if syncErr := q.out.Sync(); err == nil {
+		err = syncErr
+	}
+	return err
+}
+
+// Close 停止后台 goroutine 并写出剩余日志，之后的日志将直接同步写出。
+func (q *asyncQueue) Close() error {
+	var err error
+	q.once.Do(func() {
+		close(q.stop)
+		<-q.done
+		// 持有 writeMu 直到剩余日志写出，被唤醒的阻塞写入方只能排在队列中的日志之后同步写出
+		q.writeMu.Lock()
+		defer q.writeMu.Unlock()
+		q.mu.Lock()
+		q.closed = true
+		q.notFull.Broadcast()
+		q.mu.Unlock()
+		err = q.drainLocked()
+	})
+	if syncErr := q.Sync(); err == nil {
+		err = syncErr
+	}
+	return err
+}
+
+// asyncCore 与 zapcore.NewCore 创建的核心相同，但把编码后的日志交给 asyncQueue 写出。
+type asyncCore struct {
+	zapcore.LevelEnabler
+	enc   zapcore.Encoder
+	queue *asyncQueue
+}
+
+// newAsyncCore 创建异步输出的核心及其队列。
+func newAsyncCore(enc zapcore.Encoder, out zapcore.WriteSyncer, enab zapcore.LevelEnabler, cfg AsyncConfig) (*asyncCore, *asyncQueue) {
+	queue := newAsyncQueue(out, enc, cfg)
+	return &asyncCore{LevelEnabler: enab, enc: enc, queue: queue}, queue
+}
+
+// Level 返回最低的启用级别，供 zapcore.LevelOf 使用。
+func (c *asyncCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.LevelEnabler)
+}
+
+// With 返回附加了字段的新核心，与原核心共享队列。
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	return &asyncCore{LevelEnabler: c.LevelEnabler, enc: enc, queue: c.queue}
+}
+
+// Check 在级别允许时将自身加入待写入的核心列表。
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 编码日志并放入队列，DPanic 及以上级别的日志会立即写出。
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+	buf.Free()
+
+	if err := c.queue.push(ent.Level, data); err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// 与 zapcore.NewCore 一致，Panic 与 Fatal 日志在进程退出前必须写出
+		return c.Sync()
+	}
+	return nil
+}
+
+// Sync 写出队列中的全部日志并刷新底层输出。
+func (c *asyncCore) Sync() error {
+	return c.queue.Sync()
+}
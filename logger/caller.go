@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// callerSkip 是 GLogger 自身的方法在调用栈中占用的栈帧数，zap 需要跳过它才能找到真实的调用位置。
+const callerSkip = 1
+
+var (
+	helperFuncs    sync.Map                         // 通过 Helper 标记的函数名。
+	helperPrefixes atomic.Pointer[[]string]         // 通过 SkipCallerPackages 登记的函数名前缀。
+	hasHelpers     atomic.Bool                      // 是否登记过任何辅助函数，未登记时无需检查调用栈。
+	helperPCs      atomic.Pointer[map[uintptr]bool] // 按程序计数器缓存的判断结果，登记新的辅助函数时清空。
+)
+
+// resetHelperPCs 在登记新的辅助函数后清空缓存。
+func resetHelperPCs() {
+	helperPCs.Store(&map[uintptr]bool{})
+	hasHelpers.Store(true)
+}
+
+// Helper 将调用它的函数标记为日志辅助函数，与 testing.T.Helper 类似：
+// 辅助函数内记录的日志，其 caller 会指向调用该辅助函数的位置。
+// 适用于 response.OK 这类封装了日志记录、可能被多层包装的函数。
+//
+// 示例：
+//
+//	func logFailure(err error) {
+//		logger.Helper()
+//		monophonic.Default().Error("operation failed", zap.Error(err))
+//	}
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if _, loaded := helperFuncs.LoadOrStore(frame.Function, struct{}{}); !loaded {
+		resetHelperPCs()
+	}
+}
+
+// SkipCallerPackages 将函数名以 prefixes 中任意一项开头的函数视为日志辅助函数，
+// 用于无法调用 Helper 的第三方代码，如 "gorm.io/" 使 GORM 日志的 caller 指向业务代码。
+func SkipCallerPackages(prefixes ...string) {
+	for {
+		old := helperPrefixes.Load()
+		var merged []string
+		if old != nil {
+			merged = append(merged, *old...)
+		}
+		merged = append(merged, prefixes...)
+		if helperPrefixes.CompareAndSwap(old, &merged) {
+			resetHelperPCs()
+			return
+		}
+	}
+}
+
+// isHelper 判断函数是否为日志辅助函数。
+func isHelper(function string) bool {
+	if _, ok := helperFuncs.Load(function); ok {
+		return true
+	}
+	if prefixes := helperPrefixes.Load(); prefixes != nil {
+		for _, prefix := range *prefixes {
+			if strings.HasPrefix(function, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isHelperPC 判断程序计数器 pc 所在的函数是否为日志辅助函数，结果按 pc 缓存，
+// 使不经过辅助函数的普通调用只需查表，无需解析栈帧。
+func isHelperPC(pc uintptr) bool {
+	cache := helperPCs.Load()
+	if helper, ok := (*cache)[pc]; ok {
+		return helper
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	result := isHelper(frame.Function)
+	// 写入失败说明缓存已被替换或清空，下次调用时重新计算即可
+	next := make(map[uintptr]bool, len(*cache)+1)
+	for k, v := range *cache {
+		next[k] = v
+	}
+	next[pc] = result
+	helperPCs.CompareAndSwap(cache, &next)
+	return result
+}
+
+// helperFrames 从 skip 指定的栈帧开始（0 表示 helperFrames 的调用方），返回连续的辅助函数栈帧数。
+func helperFrames(skip int) int {
+	if !hasHelpers.Load() {
+		return 0
+	}
+	n := 0
+	var pcs [16]uintptr
+	for {
+		// runtime.Callers 的 skip 中 0 为 runtime.Callers 自身，1 为 helperFrames
+		count := runtime.Callers(skip+2+n, pcs[:])
+		if count == 0 {
+			return n
+		}
+		// runtime.Callers 为每个栈帧（包括内联函数）返回一个 pc
+		for _, pc := range pcs[:count] {
+			if !isHelperPC(pc) {
+				return n
+			}
+			n++
+		}
+	}
+}
+
+// zapLogger 返回用于记录日志的 zap.Logger，调用栈中存在辅助函数时跳过它们。
+// depth 为 zapLogger 的调用方到 GLogger 公开方法之间的栈帧数，公开方法直接调用时为0。
+func (log *GLogger) zapLogger(depth int) *zap.Logger {
+	// 跳过 zapLogger 自身、depth 个中间栈帧、GLogger 的公开方法以及 WithCallerSkip 指定的栈帧
+	if n := helperFrames(1 + depth + callerSkip + log.callerSkip); n > 0 {
+		return log.base().WithOptions(zap.AddCallerSkip(n))
+	}
+	return log.base()
+}
+
+// WithCallerSkip 返回额外跳过 skip 个栈帧的子日志记录器，
+// 用于固定包装了一层或多层的日志函数，使 caller 指向包装函数的调用方。
+// @param skip int: 额外跳过的栈帧数。
+// @return *GLogger: 子日志记录器，与原实例共享日志级别与输出。
+func (log *GLogger) WithCallerSkip(skip int) *GLogger {
+	child := log.derive(log.base().WithOptions(zap.AddCallerSkip(skip)))
+	child.callerSkip += skip
+	return child
+}
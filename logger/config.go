@@ -0,0 +1,602 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"go.opentelemetry.io/otel/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Encoding 表示日志输出的编码格式。
+type Encoding string
+
+const (
+	// EncodingConsole 控制台格式，便于开发时阅读。
+	// 输出到终端时带颜色高亮，输出到非终端或设置了 NO_COLOR 环境变量时不带颜色。
+	EncodingConsole Encoding = "console"
+	// EncodingJSON JSON 格式，便于日志采集系统解析。
+	EncodingJSON Encoding = "json"
+	// EncodingLogfmt logfmt 格式（key=value），兼顾可读性与可解析性。
+	EncodingLogfmt Encoding = "logfmt"
+)
+
+// SinkType 表示日志输出目的地的类型。
+type SinkType string
+
+const (
+	// SinkStdout 输出到标准输出。
+	SinkStdout SinkType = "stdout"
+	// SinkStderr 输出到标准错误输出。
+	SinkStderr SinkType = "stderr"
+	// SinkFile 输出到文件，并按 RotateConfig 进行切割。
+	SinkFile SinkType = "file"
+	// SinkWriter 输出到调用方提供的任意 io.Writer。
+	SinkWriter SinkType = "writer"
+	// SinkOTel 通过 OpenTelemetry 日志桥接输出到 LoggerProvider，由其负责导出到 collector。
+	SinkOTel SinkType = "otel"
+)
+
+/*
+RotateConfig 描述日志文件的切割策略，由 RotateFile 实现，MaxSize、MaxBackups、MaxAge 与 Compress 的含义与 lumberjack 一致。
+
+属性说明：
+  - MaxSize：单个日志文件最大大小，单位为MB，为0时使用100，小于0时不按大小切割。
+  - MaxBackups：保留的旧日志文件的最大数量，为0时不限制。
+  - MaxAge：旧日志文件保留的最长时间，单位天，为0时不限制。
+  - Compress：是否使用 gzip 压缩旧日志文件，Compression 非空时以 Compression 为准。
+  - Compression：旧日志文件的压缩算法，见 CompressionNone、CompressionGzip、CompressionZstd。
+  - Interval：按时间切割的周期，见 RotateHourly、RotateDaily，为空时只按大小切割。
+  - MaxTotalSize：全部旧日志文件的总大小上限，单位为MB，超出时从最早的旧文件开始删除，为0时不限制。
+  - OnRotate：旧日志文件切割并压缩完成后依次调用的函数，参数为旧文件最终的路径，在后台 goroutine 中执行。
+  - External：由外部工具（如 logrotate）切割日志文件，此时忽略以上各项。收到 SIGHUP（Windows 除外）
+    或调用 GLogger.Reopen 时重新打开文件，文件被移动或删除时在下次写入前重新创建。
+  - ExternalCheckInterval：仅 External 使用，写入前检查文件是否被移动或删除的最短间隔，不大于0时使用1秒。
+*/
+type RotateConfig struct {
+	MaxSize               int
+	MaxBackups            int
+	MaxAge                int
+	Compress              bool
+	Compression           Compression
+	Interval              RotateInterval
+	MaxTotalSize          int
+	OnRotate              []RotateHook
+	External              bool
+	ExternalCheckInterval time.Duration
+}
+
+// DefaultRotateConfig 返回默认的切割策略：单个文件100M，最多保留60个备份、30天，并压缩旧文件。
+func DefaultRotateConfig() RotateConfig {
+	return RotateConfig{
+		MaxSize:    100,
+		MaxBackups: 60,
+		MaxAge:     30,
+		Compress:   true,
+	}
+}
+
+/*
+SinkConfig 描述一个日志输出目的地，每个目的地可以拥有独立的级别、编码与切割策略。
+
+属性说明：
+  - Type：输出类型，见 SinkStdout、SinkStderr、SinkFile、SinkWriter、SinkOTel。
+  - Level：在日志级别之上额外限制该输出的最低级别，为空时不额外限制。
+  - Encoding：编码格式，为空时文件输出使用 EncodingJSON，其余输出使用 EncodingConsole。
+  - Path：日志文件路径，仅 SinkFile 使用。
+  - Rotate：文件切割策略，仅 SinkFile 使用，为 nil 时使用 DefaultRotateConfig。
+  - Writer：自定义写入目标，仅 SinkWriter 使用。
+  - LoggerProvider：OpenTelemetry 的 LoggerProvider，仅 SinkOTel 使用，为 nil 时使用全局的 LoggerProvider，
+    之后通过 global.SetLoggerProvider 设置的 LoggerProvider 同样生效。
+  - Async：异步输出策略，为 nil 时同步写入。SinkOTel 不支持，批量导出请使用 LoggerProvider 的 BatchProcessor。
+  - Route：按日志记录器名称或消息标签筛选该输出接收的日志，为 nil 时接收全部日志。
+*/
+type SinkConfig struct {
+	Type           SinkType
+	Level          string
+	Encoding       Encoding
+	Path           string
+	Rotate         *RotateConfig
+	Writer         io.Writer
+	LoggerProvider log.LoggerProvider
+	Async          *AsyncConfig
+	Route          *RouteConfig
+}
+
+/*
+Config 描述 GLogger 的完整配置。
+
+属性说明：
+  - Level：日志级别，所有输出共享，可通过 GLogger.SetLogLevel 动态调整。
+    也可以按日志记录器名称分别设置，如 "gorm=warn,http=info,*=debug"，其中 "*" 为其余日志的级别。
+  - Sinks：日志输出目的地列表，为空时默认输出到标准输出。
+  - Redact：脱敏规则，作用于全部输出，为 nil 时不脱敏。
+  - Sampling：采样与限流策略，作用于全部输出，为 nil 时不采样也不限流。
+  - CallerSkip：记录 caller 时额外跳过的栈帧数，用于统一通过包装函数记录日志的场景，Reload 不会修改此项。
+  - TraceIDGenerator：GenerateTraceId 使用的追踪ID生成器，为 nil 时使用 UUIDv4Generator。
+*/
+type Config struct {
+	Level            string
+	Sinks            []SinkConfig
+	Redact           *RedactConfig
+	Sampling         *SamplingConfig
+	CallerSkip       int
+	TraceIDGenerator TraceIDGenerator
+}
+
+// NewEncoder 根据编码格式创建对应的 zapcore.Encoder。
+// 空字符串视为 EncodingConsole，控制台格式总是带颜色高亮，未知格式返回错误。
+func NewEncoder(encoding Encoding) (zapcore.Encoder, error) {
+	return newEncoder(encoding, true)
+}
+
+// newEncoder 根据编码格式创建对应的 zapcore.Encoder，color 决定控制台格式是否带颜色高亮。
+func newEncoder(encoding Encoding, color bool) (zapcore.Encoder, error) {
+	switch encoding {
+	case "", EncodingConsole:
+		if color {
+			return GetEncoder(), nil
+		}
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	case EncodingJSON:
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	case EncodingLogfmt:
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+		return newLogfmtEncoder(encoderConfig), nil
+	default:
+		return nil, fmt.Errorf("logger: unknown encoding %q", encoding)
+	}
+}
+
+// newSinkEncoder 为输出创建编码器：未指定格式的文件输出使用 EncodingJSON，
+// 控制台格式仅在写入终端且未设置 NO_COLOR 时带颜色。
+func newSinkEncoder(sink SinkConfig) (zapcore.Encoder, error) {
+	encoding := sink.Encoding
+	if encoding == "" && sink.Type == SinkFile {
+		encoding = EncodingJSON
+	}
+	return newEncoder(encoding, sinkSupportsColor(sink))
+}
+
+// sinkSupportsColor 判断输出是否为终端，且用户没有通过 NO_COLOR 环境变量关闭颜色。
+func sinkSupportsColor(sink SinkConfig) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	switch sink.Type {
+	case SinkStdout:
+		return isTerminal(os.Stdout)
+	case SinkStderr:
+		return isTerminal(os.Stderr)
+	case SinkWriter:
+		f, ok := sink.Writer.(*os.File)
+		return ok && isTerminal(f)
+	default:
+		return false
+	}
+}
+
+// isTerminal 判断文件是否为终端。
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// newSinkWriter 根据输出配置创建对应的 zapcore.WriteSyncer。
+// 对于由本包打开的文件，同时返回用于关闭它的 io.Closer，其余输出返回 nil。
+func newSinkWriter(sink SinkConfig) (zapcore.WriteSyncer, io.Closer, error) {
+	switch sink.Type {
+	case SinkStdout:
+		return zapcore.AddSync(os.Stdout), nil, nil
+	case SinkStderr:
+		return zapcore.AddSync(os.Stderr), nil, nil
+	case SinkFile:
+		if sink.Path == "" {
+			return nil, nil, fmt.Errorf("logger: file sink requires a path")
+		}
+		rotate := DefaultRotateConfig()
+		if sink.Rotate != nil {
+			rotate = *sink.Rotate
+		}
+		if err := rotate.validate(); err != nil {
+			return nil, nil, err
+		}
+		file := NewRotateFile(sink.Path, rotate)
+		return file, file, nil
+	case SinkWriter:
+		if sink.Writer == nil {
+			return nil, nil, fmt.Errorf("logger: writer sink requires a writer")
+		}
+		return zapcore.AddSync(sink.Writer), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("logger: unknown sink type %q", sink.Type)
+	}
+}
+
+// sinkSet 记录 buildCore 创建的、需要在替换或关闭时释放的输出资源。
+type sinkSet struct {
+	sampler     *sampler
+	redact      *redactor // 作用于全部输出的脱敏规则，未配置时为 nil，也用于 GLogger.WriteChromeTrace。
+	queues      []*asyncQueue
+	files       []io.Closer
+	stopSignals func() // 停止监听 SIGHUP，没有由外部工具切割的文件时为 nil。
+}
+
+// close 先输出采样统计，再写出并停止全部异步队列，最后关闭打开的文件。
+func (s *sinkSet) close() error {
+	return s.shutdown(false)
+}
+
+// retire 与 close 相同，但文件关闭后不再重新打开，用于被 Reload 替换的输出：
+// 仍持有旧核心的写入将返回错误，而不是在原路径上重新打开文件。
+func (s *sinkSet) retire() error {
+	return s.shutdown(true)
+}
+
+// shutdown 关闭全部输出，retire 为 true 时文件关闭后不再重新打开。
+func (s *sinkSet) shutdown(retire bool) error {
+	if s.stopSignals != nil {
+		s.stopSignals()
+	}
+	var errs []error
+	if s.sampler != nil {
+		errs = append(errs, s.sampler.Close())
+	}
+	for _, q := range s.queues {
+		errs = append(errs, q.Close())
+	}
+	for _, f := range s.files {
+		if r, ok := f.(*RotateFile); ok && retire {
+			errs = append(errs, r.retire())
+			continue
+		}
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// reopen 重新打开全部日志文件。
+func (s *sinkSet) reopen() error {
+	var errs []error
+	for _, f := range s.files {
+		if r, ok := f.(interface{ Reopen() error }); ok {
+			errs = append(errs, r.Reopen())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dropped 返回全部异步队列累计丢弃的日志条数。
+func (s *sinkSet) dropped() uint64 {
+	var total uint64
+	for _, q := range s.queues {
+		total += q.Dropped()
+	}
+	return total
+}
+
+// suppressed 返回因采样与限流累计丢弃的日志条数。
+func (s *sinkSet) suppressed() uint64 {
+	if s.sampler == nil {
+		return 0
+	}
+	return s.sampler.Suppressed()
+}
+
+// buildCore 根据配置为每个输出创建独立的 zapcore.Core，并将它们合并为一个。
+// 所有输出共享同一个 level，因此修改 level 会立即作用于全部输出。
+func buildCore(cfg Config, level zapcore.LevelEnabler) (zapcore.Core, *sinkSet, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Type: SinkStdout}}
+	}
+
+	var redact *redactor
+	if cfg.Redact != nil {
+		var err error
+		if redact, err = newRedactor(*cfg.Redact); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cores := make([]zapcore.Core, 0, len(sinks))
+	set := &sinkSet{redact: redact}
+	for i, sink := range sinks {
+		enabler := sinkLevelEnabler(level, sink.Level)
+		var core zapcore.Core
+		if sink.Type == SinkOTel {
+			core = newOTelCore(sink.LoggerProvider, enabler)
+		} else {
+			encoder, err := newSinkEncoder(sink)
+			if err != nil {
+				_ = set.close()
+				return nil, nil, fmt.Errorf("sink %d: %w", i, err)
+			}
+			writer, closer, err := newSinkWriter(sink)
+			if err != nil {
+				_ = set.close()
+				return nil, nil, fmt.Errorf("sink %d: %w", i, err)
+			}
+			if closer != nil {
+				set.files = append(set.files, closer)
+			}
+			if sink.Async != nil {
+				var queue *asyncQueue
+				core, queue = newAsyncCore(encoder, writer, enabler, *sink.Async)
+				set.queues = append(set.queues, queue)
+			} else {
+				core = zapcore.NewCore(encoder, writer, enabler)
+			}
+		}
+		if redact != nil {
+			core = &redactCore{Core: core, r: redact}
+		}
+		// 路由在脱敏之前按原始消息匹配标签
+		if sink.Route != nil {
+			core = &routeCore{Core: core, route: *sink.Route}
+		}
+		cores = append(cores, core)
+	}
+
+	for _, sink := range sinks {
+		if sink.Type == SinkFile && sink.Rotate != nil && sink.Rotate.External {
+			set.stopSignals = notifyReopen(set)
+			break
+		}
+	}
+
+	core := zapcore.NewTee(cores...)
+	if cfg.Sampling != nil {
+		core, set.sampler = newSampler(core, *cfg.Sampling, func(ent zapcore.Entry) bool {
+			for _, c := range cores {
+				if sinkAccepts(c, ent) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	return core, set, nil
+}
+
+// sinkLevelEnabler 组合共享的日志级别与输出自身的级别，两者都满足时才输出。
+func sinkLevelEnabler(level zapcore.LevelEnabler, sinkLevel string) zapcore.LevelEnabler {
+	if sinkLevel == "" {
+		return level
+	}
+	minLevel := GetLogLevel(sinkLevel)
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= minLevel && level.Enabled(l)
+	})
+}
+
+// Validate 检查配置是否合法，包括日志级别、输出类型、编码格式以及各输出必要的参数。
+// 检查不会打开文件或启动后台 goroutine。
+func (cfg Config) Validate() error {
+	if err := cfg.validateLevels(); err != nil {
+		return err
+	}
+	for i, sink := range cfg.Sinks {
+		if err := sink.validate(); err != nil {
+			return fmt.Errorf("sink %d: %w", i, err)
+		}
+	}
+	if cfg.Sampling != nil {
+		if err := cfg.Sampling.validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.Redact != nil {
+		if _, err := newRedactor(*cfg.Redact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 检查单个输出的类型、编码格式、必要的参数以及切割、异步与路由策略。
+func (sink SinkConfig) validate() error {
+	switch sink.Type {
+	case SinkStdout, SinkStderr:
+	case SinkFile:
+		if sink.Path == "" {
+			return fmt.Errorf("logger: file sink requires a path")
+		}
+		if sink.Rotate != nil {
+			if err := sink.Rotate.validate(); err != nil {
+				return err
+			}
+		}
+	case SinkWriter:
+		if sink.Writer == nil {
+			return fmt.Errorf("logger: writer sink requires a writer")
+		}
+	case SinkOTel:
+		if sink.Async != nil {
+			return fmt.Errorf("logger: otel sinks do not support async")
+		}
+	default:
+		return fmt.Errorf("logger: unknown sink type %q", sink.Type)
+	}
+	if sink.Type != SinkOTel {
+		if _, err := newEncoder(sink.Encoding, false); err != nil {
+			return err
+		}
+	}
+	if sink.Async != nil {
+		if err := sink.Async.validate(); err != nil {
+			return err
+		}
+	}
+	if sink.Route != nil {
+		if err := sink.Route.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLevels 检查全局的分模块级别配置与各输出的级别是否可以识别。
+func (cfg Config) validateLevels() error {
+	if _, _, err := parseLevelSpec(cfg.Level); err != nil {
+		return err
+	}
+	for i, sink := range cfg.Sinks {
+		if err := validateLevel(sink.Level); err != nil {
+			return fmt.Errorf("sink %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateLevel 检查日志级别字符串是否可以识别，空字符串视为合法。
+func validateLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	if _, err := zapcore.ParseLevel(level); err != nil {
+		return fmt.Errorf("logger: unknown level %q", level)
+	}
+	return nil
+}
+
+// firstFilePath 返回配置中第一个文件输出的路径，没有文件输出时返回空字符串。
+func firstFilePath(cfg Config) string {
+	for _, sink := range cfg.Sinks {
+		if sink.Type == SinkFile {
+			return sink.Path
+		}
+	}
+	return ""
+}
+
+// rootLevelSpec 将 Config.Level 转换为完整的分模块级别配置，
+// 使单一级别同样会清除之前设置的模块级别。
+func rootLevelSpec(level string) string {
+	if strings.Contains(level, "=") {
+		return level
+	}
+	return "*=" + GetLogLevel(level).String()
+}
+
+// NewGLogger 根据配置创建 GLogger 实例。
+// 配置中存在未知的日志级别、输出类型、编码格式或缺少必要参数时返回错误。
+func NewGLogger(cfg Config) (*GLogger, error) {
+	// rootLevelSpec 与 sinkLevelEnabler 会将未知级别视为 info，因此需要先检查
+	if err := cfg.validateLevels(); err != nil {
+		return nil, err
+	}
+	levels := newLevelTree(zapcore.InfoLevel)
+	if err := levels.set("", rootLevelSpec(cfg.Level)); err != nil {
+		return nil, err
+	}
+	core, sinks, err := buildCore(cfg, levels)
+	if err != nil {
+		return nil, err
+	}
+
+	reloadable := newReloadableCore(core, sinks)
+	glogger := &GLogger{
+		ZapLogger:  zap.New(&levelTreeCore{Core: reloadable, levels: levels}, zap.AddCaller(), zap.AddCallerSkip(callerSkip+cfg.CallerSkip)),
+		LogLevel:   cfg.Level,
+		LogPath:    firstFilePath(cfg),
+		levels:     levels,
+		core:       reloadable,
+		callerSkip: cfg.CallerSkip,
+	}
+	glogger.traceIDs.Store(newTraceIDSource(cfg.TraceIDGenerator))
+	return glogger, nil
+}
+
+// NewGLoggerFromCore 创建写入 core 的 GLogger，输出与编码均由 core 决定，
+// 用于接入自定义的 zapcore.Core，如测试中捕获日志的核心。
+// 初始级别为 debug，即全部交由 core 判断，仍可通过 SetLogLevel 在其之上进一步限制。
+// 此实例不支持 Reload，Close 只会刷新日志。
+func NewGLoggerFromCore(core zapcore.Core) *GLogger {
+	levels := newLevelTree(zapcore.DebugLevel)
+	glogger := &GLogger{
+		ZapLogger: zap.New(&levelTreeCore{Core: core, levels: levels}, zap.AddCaller(), zap.AddCallerSkip(callerSkip)),
+		LogLevel:  zapcore.DebugLevel.String(),
+		levels:    levels,
+	}
+	glogger.traceIDs.Store(newTraceIDSource(nil))
+	return glogger
+}
+
+// Reload 按新的配置替换日志级别与全部输出，已派生的子日志记录器同样生效。
+// 配置不合法时返回错误，并保持原有配置不变；被替换的异步输出会先写出队列中的日志，
+// 被替换的日志文件关闭后不会再重新打开。
+// 只有通过 NewGLogger 创建的实例才支持重新加载。
+func (log *GLogger) Reload(cfg Config) error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if log.core == nil {
+		return fmt.Errorf("logger: GLogger was not created by NewGLogger and cannot be reloaded")
+	}
+	// buildCore 会先检查配置，不合法时不会打开任何输出
+	core, sinks, err := buildCore(cfg, log.levels)
+	if err != nil {
+		return err
+	}
+	if err := log.levels.set("", rootLevelSpec(cfg.Level)); err != nil {
+		_ = sinks.close()
+		return err
+	}
+	previous := log.core.swap(core, sinks)
+	log.LogLevel = cfg.Level
+	log.LogPath = firstFilePath(cfg)
+	if traceIDs := log.traceIDs.Load(); traceIDs != nil {
+		traceIDs.set(cfg.TraceIDGenerator)
+	} else {
+		log.traceIDs.Store(newTraceIDSource(cfg.TraceIDGenerator))
+	}
+	// 新配置已经生效，旧输出关闭时的错误（如标准输出不支持 Sync）不影响重新加载的结果
+	_ = previous.retire()
+	return nil
+}
+
+// Reopen 关闭并按原路径重新打开全部文件输出，文件不存在时创建，用于配合外部工具（如 logrotate）切割日志文件。
+// 文件输出设置了 RotateConfig.External 时，收到 SIGHUP 也会自动调用。子日志记录器与原实例共享输出。
+// @return error: 重新打开失败时返回的错误，此时继续写入原来的文件。
+func (log *GLogger) Reopen() error {
+	if log.core == nil {
+		return nil
+	}
+	return log.core.sinks().reopen()
+}
+
+// Dropped 返回异步输出因队列已满而累计丢弃的日志条数，未使用异步输出时总是返回0。
+// 重新加载配置后从0开始计数。
+func (log *GLogger) Dropped() uint64 {
+	if log.core == nil {
+		return 0
+	}
+	return log.core.sinks().dropped()
+}
+
+// Suppressed 返回因采样与限流累计丢弃的日志条数，未配置采样时总是返回0。
+// 重新加载配置后从0开始计数。
+func (log *GLogger) Suppressed() uint64 {
+	if log.core == nil {
+		return 0
+	}
+	return log.core.sinks().suppressed()
+}
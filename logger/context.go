@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceIDKey 是追踪ID在日志中的字段名。
+const TraceIDKey = "traceId"
+
+// contextKey 是日志相关数据在 context.Context 中的键。
+type contextKey struct{}
+
+// contextData 是保存在 context.Context 中的追踪ID与日志字段。
+type contextData struct {
+	traceID     string
+	fields      []zapcore.Field
+	traceparent *Traceparent  // 调用方传入的 traceparent，用于向下游继续传播。
+	spans       *spanRecorder // 同一追踪中的内置 span，见 StartSpan。
+	spanID      string        // 当前所在的内置 span，之后开始的 span 以其为父级。
+}
+
+// dataFromContext 取出 ctx 中保存的日志数据，不存在时返回零值。
+func dataFromContext(ctx context.Context) contextData {
+	if ctx == nil {
+		return contextData{}
+	}
+	data, _ := ctx.Value(contextKey{}).(contextData)
+	return data
+}
+
+// WithTraceID 返回携带追踪ID的 context.Context，之前保存的字段保持不变。
+// @param ctx context.Context: 父级上下文。
+// @param traceID string: 追踪ID，通常由 GLogger.GenerateTraceId 生成。
+// @return context.Context: 携带追踪ID的新上下文。
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	data := dataFromContext(ctx)
+	data.traceID = traceID
+	return context.WithValue(ctx, contextKey{}, data)
+}
+
+// WithFields 返回追加了日志字段的 context.Context，之前保存的追踪ID与字段保持不变。
+// @param ctx context.Context: 父级上下文。
+// @param fields ...zapcore.Field: 需要随上下文传递的日志字段。
+// @return context.Context: 携带日志字段的新上下文。
+func WithFields(ctx context.Context, fields ...zapcore.Field) context.Context {
+	data := dataFromContext(ctx)
+	merged := make([]zapcore.Field, 0, len(data.fields)+len(fields))
+	merged = append(merged, data.fields...)
+	data.fields = append(merged, fields...)
+	return context.WithValue(ctx, contextKey{}, data)
+}
+
+// WithTraceparent 返回携带调用方 traceparent 的 context.Context，之前保存的追踪ID与字段保持不变。
+// 向下游发起请求时（见 middleware.Transport）会沿用其中的 trace-id 与采样标志。
+// @param ctx context.Context: 父级上下文。
+// @param tp Traceparent: 调用方传入的 traceparent。
+// @return context.Context: 携带 traceparent 的新上下文。
+func WithTraceparent(ctx context.Context, tp Traceparent) context.Context {
+	data := dataFromContext(ctx)
+	data.traceparent = &tp
+	return context.WithValue(ctx, contextKey{}, data)
+}
+
+// TraceparentFromContext 返回 ctx 中保存的 traceparent，不存在时第二个返回值为 false。
+func TraceparentFromContext(ctx context.Context) (Traceparent, bool) {
+	if tp := dataFromContext(ctx).traceparent; tp != nil {
+		return *tp, true
+	}
+	return Traceparent{}, false
+}
+
+// TraceIDFromContext 返回 ctx 中保存的追踪ID，不存在时返回空字符串。
+func TraceIDFromContext(ctx context.Context) string {
+	return dataFromContext(ctx).traceID
+}
+
+// FieldsFromContext 返回 ctx 中保存的日志字段，存在追踪ID时以 TraceIDKey 字段排在最前。
+// ctx 中存在有效的 OpenTelemetry span 时，在追踪ID之后附加 trace_id、span_id 与 trace_flags 字段，
+// 使日志可以与 OpenTelemetry 的追踪数据关联。
+func FieldsFromContext(ctx context.Context) []zapcore.Field {
+	data := dataFromContext(ctx)
+	span := spanContextFields(ctx)
+	if data.traceID == "" && span == nil {
+		return data.fields
+	}
+	fields := make([]zapcore.Field, 0, len(data.fields)+len(span)+1)
+	if data.traceID != "" {
+		fields = append(fields, zap.String(TraceIDKey, data.traceID))
+	}
+	fields = append(fields, span...)
+	return append(fields, data.fields...)
+}
+
+// withContextFields 将 ctx 中的字段放在调用方字段之前。
+func withContextFields(ctx context.Context, fields []zapcore.Field) []zapcore.Field {
+	ctxFields := FieldsFromContext(ctx)
+	if len(ctxFields) == 0 {
+		return fields
+	}
+	merged := make([]zapcore.Field, 0, len(ctxFields)+len(fields))
+	merged = append(merged, ctxFields...)
+	return append(merged, fields...)
+}
+
+// WithContext 返回附加了 ctx 中追踪ID与字段的子日志记录器，适合在请求处理过程中反复使用。
+// 子日志记录器与原实例共享日志级别与输出。
+// @param ctx context.Context: 携带追踪ID与字段的上下文。
+// @return *GLogger: 请求范围内的子日志记录器。
+func (log *GLogger) WithContext(ctx context.Context) *GLogger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return log
+	}
+	return log.derive(log.base().With(fields...))
+}
+
+// derive 以 zapLogger 创建与原实例共享日志级别与输出的子日志记录器。
+func (log *GLogger) derive(zapLogger *zap.Logger) *GLogger {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	child := &GLogger{
+		ZapLogger:  zapLogger,
+		LogLevel:   log.LogLevel,
+		LogPath:    log.LogPath,
+		name:       log.name,
+		levels:     log.levelTree(),
+		core:       log.core,
+		callerSkip: log.callerSkip,
+	}
+	child.traceIDs.Store(log.traceIDs.Load())
+	return child
+}
+
+// DebugCtx 记录调试级别的日志，并自动附加 ctx 中的追踪ID与字段。
+// @param ctx context.Context: 携带追踪ID与字段的上下文。
+// @param msg string: 日志消息。
+// @param fields ...zapcore.Field: 额外的结构化日志字段。
+func (log *GLogger) DebugCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	log.zapLogger(0).Debug(msg, withContextFields(ctx, fields)...)
+}
+
+// InfoCtx 记录信息级别的日志，并自动附加 ctx 中的追踪ID与字段。
+// @param ctx context.Context: 携带追踪ID与字段的上下文。
+// @param msg string: 日志消息。
+// @param fields ...zapcore.Field: 额外的结构化日志字段。
+func (log *GLogger) InfoCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	log.zapLogger(0).Info(msg, withContextFields(ctx, fields)...)
+}
+
+// WarnCtx 记录警告级别的日志，并自动附加 ctx 中的追踪ID与字段。
+// @param ctx context.Context: 携带追踪ID与字段的上下文。
+// @param msg string: 日志消息。
+// @param fields ...zapcore.Field: 额外的结构化日志字段。
+func (log *GLogger) WarnCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	log.zapLogger(0).Warn(msg, withContextFields(ctx, fields)...)
+}
+
+// ErrorCtx 记录错误级别的日志，并自动附加 ctx 中的追踪ID与字段。
+// @param ctx context.Context: 携带追踪ID与字段的上下文。
+// @param msg string: 日志消息。
+// @param fields ...zapcore.Field: 额外的结构化日志字段。
+func (log *GLogger) ErrorCtx(ctx context.Context, msg string, fields ...zapcore.Field) {
+	log.zapLogger(0).Error(msg, withContextFields(ctx, fields)...)
+}
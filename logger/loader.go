@@ -0,0 +1,578 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// 以下环境变量用于 ConfigFromEnv，变量名统一以 MONOPHONIC_ 开头。
+const (
+	EnvConfig             = "MONOPHONIC_CONFIG"                // 配置文件路径，设置后优先从文件加载。
+	EnvLevel              = "MONOPHONIC_LEVEL"                 // 日志级别，同时会覆盖配置文件中的级别。
+	EnvOutputs            = "MONOPHONIC_OUTPUTS"               // 逗号分隔的输出类型，如 "stdout,file"。
+	EnvFormat             = "MONOPHONIC_FORMAT"                // 所有输出使用的编码格式。
+	EnvFile               = "MONOPHONIC_FILE"                  // 文件输出的路径。
+	EnvRotateMaxSize      = "MONOPHONIC_ROTATE_MAX_SIZE"       // 单个日志文件最大大小，单位为MB。
+	EnvRotateMaxBackups   = "MONOPHONIC_ROTATE_MAX_BACKUPS"    // 保留的旧日志文件的最大数量。
+	EnvRotateMaxAge       = "MONOPHONIC_ROTATE_MAX_AGE"        // 旧日志文件保留的最长时间，单位天。
+	EnvRotateCompress     = "MONOPHONIC_ROTATE_COMPRESS"       // 是否压缩旧日志文件。
+	EnvRotateCompression  = "MONOPHONIC_ROTATE_COMPRESSION"    // 旧日志文件的压缩算法，见 Compression。
+	EnvRotateInterval     = "MONOPHONIC_ROTATE_INTERVAL"       // 按时间切割的周期，见 RotateInterval。
+	EnvRotateMaxTotalSize = "MONOPHONIC_ROTATE_MAX_TOTAL_SIZE" // 全部旧日志文件的总大小上限，单位为MB。
+	EnvRotateExternal     = "MONOPHONIC_ROTATE_EXTERNAL"       // 是否由外部工具（如 logrotate）切割日志文件。
+	EnvRedact             = "MONOPHONIC_REDACT"                // 是否启用 DefaultRedactConfig 中的脱敏规则。
+	EnvTraceID            = "MONOPHONIC_TRACE_ID"              // 追踪ID的格式，见 TraceIDFormat。
+	EnvTraceIDNode        = "MONOPHONIC_TRACE_ID_NODE"         // Snowflake 追踪ID的节点ID。
+)
+
+// defaultWatchInterval 是 WatchConfigFile 默认的轮询间隔。
+const defaultWatchInterval = 5 * time.Second
+
+/*
+RotateFileConfig 是 RotateConfig 在配置文件中的表示，未设置的项使用 DefaultRotateConfig 中的值。
+
+示例（YAML）：
+
+	rotation:
+	  max_size: 100
+	  max_backups: 60
+	  max_age: 30
+	  compress: true
+	  compression: zstd
+	  interval: daily
+	  max_total_size: 2048
+	  external: false
+*/
+type RotateFileConfig struct {
+	MaxSize      *int           `json:"max_size" yaml:"max_size"`
+	MaxBackups   *int           `json:"max_backups" yaml:"max_backups"`
+	MaxAge       *int           `json:"max_age" yaml:"max_age"`
+	Compress     *bool          `json:"compress" yaml:"compress"`
+	Compression  Compression    `json:"compression" yaml:"compression"`
+	Interval     RotateInterval `json:"interval" yaml:"interval"`
+	MaxTotalSize *int           `json:"max_total_size" yaml:"max_total_size"`
+	External     *bool          `json:"external" yaml:"external"`
+}
+
+/*
+AsyncFileConfig 是 AsyncConfig 在配置文件中的表示，未设置的项使用 DefaultAsyncConfig 中的值。
+
+示例（YAML）：
+
+	async:
+	  queue_size: 4096
+	  flush_interval: 500ms
+	  overflow: drop_below_level
+	  drop_level: warn
+*/
+type AsyncFileConfig struct {
+	QueueSize     int            `json:"queue_size" yaml:"queue_size"`
+	BatchSize     int            `json:"batch_size" yaml:"batch_size"`
+	FlushInterval string         `json:"flush_interval" yaml:"flush_interval"`
+	Overflow      OverflowPolicy `json:"overflow" yaml:"overflow"`
+	DropLevel     string         `json:"drop_level" yaml:"drop_level"`
+}
+
+/*
+RouteFileConfig 是 RouteConfig 在配置文件中的表示。
+
+示例（YAML），将 SQL 日志单独写入 sql.log：
+
+	route:
+	  loggers: [gorm]
+	  tags: ["[GORM]"]
+	  exclude: false
+*/
+type RouteFileConfig struct {
+	Loggers []string `json:"loggers" yaml:"loggers"`
+	Tags    []string `json:"tags" yaml:"tags"`
+	Exclude bool     `json:"exclude" yaml:"exclude"`
+}
+
+// OutputFileConfig 是 SinkConfig 在配置文件中的表示，不支持 SinkWriter。
+type OutputFileConfig struct {
+	Type     SinkType          `json:"type" yaml:"type"`
+	Level    string            `json:"level" yaml:"level"`
+	Format   Encoding          `json:"format" yaml:"format"`
+	Path     string            `json:"path" yaml:"path"`
+	Rotation *RotateFileConfig `json:"rotation" yaml:"rotation"`
+	Async    *AsyncFileConfig  `json:"async" yaml:"async"`
+	Route    *RouteFileConfig  `json:"route" yaml:"route"`
+}
+
+/*
+RedactFileConfig 是 RedactConfig 在配置文件中的表示。
+defaults 为 true 时以 DefaultRedactConfig 为基础，其余各项追加到默认规则之后，mask 非空时替换默认的替换文本。
+
+示例（YAML）：
+
+	redact:
+	  defaults: true
+	  keys: [id_number]
+	  json_paths: [query.user.card]
+*/
+type RedactFileConfig struct {
+	Defaults  bool     `json:"defaults" yaml:"defaults"`
+	Keys      []string `json:"keys" yaml:"keys"`
+	Patterns  []string `json:"patterns" yaml:"patterns"`
+	JSONPaths []string `json:"json_paths" yaml:"json_paths"`
+	Mask      string   `json:"mask" yaml:"mask"`
+}
+
+/*
+SamplingFileConfig 是 SamplingConfig 在配置文件中的表示，first 与 thereafter 未设置时使用 DefaultSamplingConfig 中的值。
+
+示例（YAML）：
+
+	sampling:
+	  interval: 1s
+	  first: 10
+	  thereafter: 100
+	  rate: 500
+	  summary_interval: 30s
+*/
+type SamplingFileConfig struct {
+	Interval        string  `json:"interval" yaml:"interval"`
+	First           *int    `json:"first" yaml:"first"`
+	Thereafter      *int    `json:"thereafter" yaml:"thereafter"`
+	Rate            float64 `json:"rate" yaml:"rate"`
+	Burst           int     `json:"burst" yaml:"burst"`
+	SummaryInterval string  `json:"summary_interval" yaml:"summary_interval"`
+}
+
+/*
+TraceIDFileConfig 是追踪ID生成器在配置文件中的表示，format 见 TraceIDFormat，node 只用于 snowflake。
+
+示例（YAML）：
+
+	trace_id:
+	  format: snowflake
+	  node: 3
+*/
+type TraceIDFileConfig struct {
+	Format TraceIDFormat `json:"format" yaml:"format"`
+	Node   int64         `json:"node" yaml:"node"`
+}
+
+/*
+FileConfig 是 Config 在 YAML/JSON 配置文件中的表示。
+
+示例（YAML）：
+
+	level: info
+	outputs:
+	  - type: stdout
+	    format: console
+	  - type: file
+	    path: tmp/run.log
+	    format: json
+	    rotation:
+	      max_size: 50
+	redact:
+	  defaults: true
+*/
+type FileConfig struct {
+	Level    string              `json:"level" yaml:"level"`
+	Outputs  []OutputFileConfig  `json:"outputs" yaml:"outputs"`
+	Redact   *RedactFileConfig   `json:"redact" yaml:"redact"`
+	Sampling *SamplingFileConfig `json:"sampling" yaml:"sampling"`
+	TraceID  *TraceIDFileConfig  `json:"trace_id" yaml:"trace_id"`
+}
+
+// resolve 将 RotateFileConfig 与默认切割策略合并。
+func (r *RotateFileConfig) resolve() *RotateConfig {
+	rotate := DefaultRotateConfig()
+	if r == nil {
+		return &rotate
+	}
+	if r.MaxSize != nil {
+		rotate.MaxSize = *r.MaxSize
+	}
+	if r.MaxBackups != nil {
+		rotate.MaxBackups = *r.MaxBackups
+	}
+	if r.MaxAge != nil {
+		rotate.MaxAge = *r.MaxAge
+	}
+	if r.Compress != nil {
+		rotate.Compress = *r.Compress
+	}
+	if r.Compression != "" {
+		rotate.Compression = r.Compression
+	}
+	if r.Interval != "" {
+		rotate.Interval = r.Interval
+	}
+	if r.MaxTotalSize != nil {
+		rotate.MaxTotalSize = *r.MaxTotalSize
+	}
+	if r.External != nil {
+		rotate.External = *r.External
+	}
+	return &rotate
+}
+
+// resolve 将 AsyncFileConfig 转换为 AsyncConfig。
+func (a *AsyncFileConfig) resolve() (*AsyncConfig, error) {
+	if a == nil {
+		return nil, nil
+	}
+	async := AsyncConfig{
+		QueueSize: a.QueueSize,
+		BatchSize: a.BatchSize,
+		Overflow:  a.Overflow,
+		DropLevel: a.DropLevel,
+	}
+	if a.FlushInterval != "" {
+		interval, err := time.ParseDuration(a.FlushInterval)
+		if err != nil {
+			return nil, fmt.Errorf("logger: invalid flush_interval %q", a.FlushInterval)
+		}
+		async.FlushInterval = interval
+	}
+	return &async, nil
+}
+
+// resolve 将 RouteFileConfig 转换为 RouteConfig。
+func (r *RouteFileConfig) resolve() *RouteConfig {
+	if r == nil {
+		return nil
+	}
+	return &RouteConfig{Loggers: r.Loggers, Tags: r.Tags, Exclude: r.Exclude}
+}
+
+// resolve 将 RedactFileConfig 转换为 RedactConfig。
+func (r *RedactFileConfig) resolve() *RedactConfig {
+	if r == nil {
+		return nil
+	}
+	var redact RedactConfig
+	if r.Defaults {
+		redact = DefaultRedactConfig()
+	}
+	redact.Keys = append(redact.Keys, r.Keys...)
+	redact.Patterns = append(redact.Patterns, r.Patterns...)
+	redact.JSONPaths = append(redact.JSONPaths, r.JSONPaths...)
+	if r.Mask != "" {
+		redact.Mask = r.Mask
+	}
+	return &redact
+}
+
+// resolve 将 SamplingFileConfig 转换为 SamplingConfig。
+func (s *SamplingFileConfig) resolve() (*SamplingConfig, error) {
+	if s == nil {
+		return nil, nil
+	}
+	sampling := DefaultSamplingConfig()
+	if s.First != nil {
+		sampling.First = *s.First
+	}
+	if s.Thereafter != nil {
+		sampling.Thereafter = *s.Thereafter
+	}
+	sampling.Rate = s.Rate
+	sampling.Burst = s.Burst
+	for _, d := range []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"interval", s.Interval, &sampling.Interval},
+		{"summary_interval", s.SummaryInterval, &sampling.SummaryInterval},
+	} {
+		if d.value == "" {
+			continue
+		}
+		interval, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("logger: invalid sampling %s %q", d.name, d.value)
+		}
+		*d.target = interval
+	}
+	return &sampling, nil
+}
+
+// resolve 将 TraceIDFileConfig 转换为 TraceIDGenerator。
+func (t *TraceIDFileConfig) resolve() (TraceIDGenerator, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return NewTraceIDGenerator(t.Format, t.Node)
+}
+
+// Config 将文件配置转换为 Config，并检查其是否合法。
+func (f FileConfig) Config() (Config, error) {
+	sampling, err := f.Sampling.resolve()
+	if err != nil {
+		return Config{}, err
+	}
+	traceIDs, err := f.TraceID.resolve()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{Level: f.Level, Redact: f.Redact.resolve(), Sampling: sampling, TraceIDGenerator: traceIDs}
+	for i, output := range f.Outputs {
+		if output.Type == SinkWriter {
+			return Config{}, fmt.Errorf("output %d: logger: writer outputs cannot be configured from a file", i)
+		}
+		if output.Type != SinkFile && (output.Path != "" || output.Rotation != nil) {
+			return Config{}, fmt.Errorf("output %d: logger: path and rotation are only valid for file outputs", i)
+		}
+		sink := SinkConfig{
+			Type:     output.Type,
+			Level:    output.Level,
+			Encoding: output.Format,
+			Path:     output.Path,
+		}
+		if output.Type == SinkFile {
+			sink.Rotate = output.Rotation.resolve()
+		}
+		async, err := output.Async.resolve()
+		if err != nil {
+			return Config{}, fmt.Errorf("output %d: %w", i, err)
+		}
+		sink.Async = async
+		sink.Route = output.Route.resolve()
+		cfg.Sinks = append(cfg.Sinks, sink)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// ParseConfig 解析 YAML 或 JSON 格式的配置内容，format 为 "yaml"、"yml" 或 "json"。
+// 配置中出现未知字段时返回错误，以便尽早发现拼写错误。
+func ParseConfig(data []byte, format string) (Config, error) {
+	var f FileConfig
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, fmt.Errorf("logger: invalid yaml config: %w", err)
+		}
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&f); err != nil {
+			return Config{}, fmt.Errorf("logger: invalid json config: %w", err)
+		}
+	default:
+		return Config{}, fmt.Errorf("logger: unknown config format %q", format)
+	}
+	return f.Config()
+}
+
+// LoadConfigFile 读取并解析配置文件，根据扩展名（.yaml、.yml、.json）选择格式。
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("logger: read config: %w", err)
+	}
+	cfg, err := ParseConfig(data, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// applyEnvLevel 在设置了 MONOPHONIC_LEVEL 时用它覆盖配置文件中的级别。
+func applyEnvLevel(cfg *Config) {
+	if level := os.Getenv(EnvLevel); level != "" {
+		cfg.Level = level
+	}
+}
+
+// ConfigFromEnv 根据 MONOPHONIC_* 环境变量构建配置。
+// 设置了 MONOPHONIC_CONFIG 时从该文件加载，此时仅 MONOPHONIC_LEVEL 会覆盖文件中的级别；
+// 否则按 MONOPHONIC_OUTPUTS 等变量组装配置，未设置输出时默认输出到标准输出。
+func ConfigFromEnv() (Config, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		cfg, err := LoadConfigFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		applyEnvLevel(&cfg)
+		return cfg, cfg.Validate()
+	}
+
+	f := FileConfig{Level: os.Getenv(EnvLevel)}
+	if value := os.Getenv(EnvRedact); value != "" {
+		redact, err := strconv.ParseBool(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("environment: logger: %s must be a boolean, got %q", EnvRedact, value)
+		}
+		if redact {
+			f.Redact = &RedactFileConfig{Defaults: true}
+		}
+	}
+	if format := os.Getenv(EnvTraceID); format != "" {
+		f.TraceID = &TraceIDFileConfig{Format: TraceIDFormat(format)}
+		if value := os.Getenv(EnvTraceIDNode); value != "" {
+			node, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return Config{}, fmt.Errorf("environment: logger: %s must be an integer, got %q", EnvTraceIDNode, value)
+			}
+			f.TraceID.Node = node
+		}
+	}
+	rotation, err := rotateFromEnv()
+	if err != nil {
+		return Config{}, err
+	}
+	for _, output := range strings.Split(os.Getenv(EnvOutputs), ",") {
+		output = strings.TrimSpace(output)
+		if output == "" {
+			continue
+		}
+		o := OutputFileConfig{Type: SinkType(output), Format: Encoding(os.Getenv(EnvFormat))}
+		if o.Type == SinkFile {
+			o.Path = os.Getenv(EnvFile)
+			o.Rotation = rotation
+		}
+		f.Outputs = append(f.Outputs, o)
+	}
+	if len(f.Outputs) == 0 {
+		f.Outputs = []OutputFileConfig{{Type: SinkStdout, Format: Encoding(os.Getenv(EnvFormat))}}
+	}
+
+	cfg, err := f.Config()
+	if err != nil {
+		return Config{}, fmt.Errorf("environment: %w", err)
+	}
+	return cfg, nil
+}
+
+// rotateFromEnv 读取 MONOPHONIC_ROTATE_* 环境变量。
+func rotateFromEnv() (*RotateFileConfig, error) {
+	var r RotateFileConfig
+	for name, target := range map[string]**int{
+		EnvRotateMaxSize:      &r.MaxSize,
+		EnvRotateMaxBackups:   &r.MaxBackups,
+		EnvRotateMaxAge:       &r.MaxAge,
+		EnvRotateMaxTotalSize: &r.MaxTotalSize,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("environment: logger: %s must be an integer, got %q", name, value)
+		}
+		*target = &n
+	}
+	for name, target := range map[string]**bool{
+		EnvRotateCompress: &r.Compress,
+		EnvRotateExternal: &r.External,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("environment: logger: %s must be a boolean, got %q", name, value)
+		}
+		*target = &b
+	}
+	r.Compression = Compression(os.Getenv(EnvRotateCompression))
+	r.Interval = RotateInterval(os.Getenv(EnvRotateInterval))
+	return &r, nil
+}
+
+// ConfigWatcher 定期检查配置文件，内容变化时重新加载到 GLogger。
+type ConfigWatcher struct {
+	glogger  *GLogger
+	path     string
+	interval time.Duration
+	last     []byte
+	stop     chan struct{}
+	once     sync.Once
+	done     chan struct{}
+}
+
+// WatchConfigFile 以轮询方式监听配置文件，interval 不大于0时使用5秒。
+// 文件内容变化后调用 GLogger.Reload 应用新配置，设置了 MONOPHONIC_LEVEL 时仍以它覆盖文件中的级别；
+// 新配置不合法或无法读取时记录错误并保留原有配置。
+// 使用完毕后需调用 Stop 结束监听。
+func WatchConfigFile(glogger *GLogger, path string, interval time.Duration) *ConfigWatcher {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	w := &ConfigWatcher{
+		glogger:  glogger,
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	w.last, _ = os.ReadFile(path)
+	go w.run()
+	return w
+}
+
+// run 按 interval 轮询配置文件，直到 Stop 被调用。
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 在文件内容变化时重新加载配置。
+func (w *ConfigWatcher) check() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		if w.last != nil {
+			w.glogger.Error("[ConfigWatcher] failed to read config, keeping previous config",
+				zap.String("path", w.path), zap.Error(err))
+			w.last = nil
+		}
+		return
+	}
+	if w.last != nil && bytes.Equal(data, w.last) {
+		return
+	}
+	w.last = data
+
+	cfg, err := ParseConfig(data, strings.TrimPrefix(filepath.Ext(w.path), "."))
+	if err == nil {
+		// 与 ConfigFromEnv 一致，MONOPHONIC_LEVEL 优先于文件中的级别
+		applyEnvLevel(&cfg)
+		err = w.glogger.Reload(cfg)
+	}
+	if err != nil {
+		w.glogger.Error("[ConfigWatcher] invalid config, keeping previous config",
+			zap.String("path", w.path), zap.Error(err))
+		return
+	}
+	w.glogger.Info("[ConfigWatcher] config reloaded", zap.String("path", w.path), zap.String("level", cfg.Level))
+}
+
+// Stop 结束监听，可重复调用。
+func (w *ConfigWatcher) Stop() {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+}
@@ -1,6 +1,14 @@
 package logger
 
-import "go.uber.org/zap/zapcore"
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
 
 // GetLogLevel 根据输入的日志级别字符串，转换并返回对应的 zapcore.Level 枚举值。
 // 此函数支持将常见的日志级别字符串（如 "debug", "info", "warn", "error", "fatal", "panic"）
@@ -23,3 +31,183 @@ func GetLogLevel(level string) zapcore.Level {
 	}
 	return lv
 }
+
+// ValidLevel 判断 level 是否为可以识别的日志级别或分模块级别配置（如 "gorm=warn,*=debug"），空字符串视为合法。
+func ValidLevel(level string) bool {
+	_, _, err := parseLevelSpec(level)
+	return err == nil
+}
+
+// parseLevelSpec 解析形如 "db=warn,http=info,*=debug" 的分模块级别配置。
+// 不含 "=" 的字符串视为单一级别，等价于 "*=<level>"。
+// 返回值 root 为 "*" 对应的级别，未设置时为 nil；modules 为各模块的级别。
+func parseLevelSpec(spec string) (root *zapcore.Level, modules map[string]zapcore.Level, err error) {
+	if !strings.Contains(spec, "=") {
+		if spec == "" {
+			return nil, nil, nil
+		}
+		lv, err := zapcore.ParseLevel(spec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("logger: unknown level %q", spec)
+		}
+		return &lv, nil, nil
+	}
+
+	modules = map[string]zapcore.Level{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(item, "=")
+		name, level = strings.TrimSpace(name), strings.TrimSpace(level)
+		if !ok || name == "" {
+			return nil, nil, fmt.Errorf("logger: invalid level spec %q, want name=level", item)
+		}
+		lv, err := zapcore.ParseLevel(level)
+		if err != nil {
+			return nil, nil, fmt.Errorf("logger: unknown level %q for %q", level, name)
+		}
+		if name == "*" {
+			root = &lv
+			continue
+		}
+		modules[name] = lv
+	}
+	return root, modules, nil
+}
+
+// levelTree 保存全局日志级别（"*"）以及按日志记录器名称设置的模块级别。
+// 名称按 "." 分级，"db.query" 未单独设置时沿用 "db" 的级别，再沿用全局级别。
+type levelTree struct {
+	root    zap.AtomicLevel
+	mu      sync.Mutex                               // 保护 modules 的写入。
+	modules atomic.Pointer[map[string]zapcore.Level] // 只读快照，写入时整体替换。
+}
+
+// newLevelTree 以 level 作为全局级别创建 levelTree。
+func newLevelTree(level zapcore.Level) *levelTree {
+	t := &levelTree{root: zap.NewAtomicLevelAt(level)}
+	t.modules.Store(&map[string]zapcore.Level{})
+	return t
+}
+
+// Enabled 只要全局级别或任一模块级别允许即返回 true，具体的过滤由 levelFor 完成。
+func (t *levelTree) Enabled(l zapcore.Level) bool {
+	if t.root.Enabled(l) {
+		return true
+	}
+	for _, lv := range *t.modules.Load() {
+		if l >= lv {
+			return true
+		}
+	}
+	return false
+}
+
+// levelFor 返回名称为 name 的日志记录器生效的级别。
+func (t *levelTree) levelFor(name string) zapcore.Level {
+	modules := *t.modules.Load()
+	for name != "" && len(modules) > 0 {
+		if lv, ok := modules[name]; ok {
+			return lv
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return t.root.Level()
+}
+
+// set 按分模块级别配置修改级别，name 为配置所属的日志记录器名称，配置中的模块名相对于它。
+// 对根日志记录器（name 为空）设置分模块配置时，未出现在配置中的模块恢复为沿用全局级别。
+func (t *levelTree) set(name string, spec string) error {
+	root, modules, err := parseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	next := map[string]zapcore.Level{}
+	if name != "" || modules == nil {
+		for k, v := range *t.modules.Load() {
+			next[k] = v
+		}
+	}
+	for module, lv := range modules {
+		next[joinName(name, module)] = lv
+	}
+	if root != nil {
+		if name == "" {
+			t.root.SetLevel(*root)
+		} else {
+			next[name] = *root
+		}
+	}
+	t.modules.Store(&next)
+	return nil
+}
+
+// unset 删除名称为 name 的模块级别，之后该名称沿用上级名称或全局级别，下级名称单独设置的级别保持不变。
+func (t *levelTree) unset(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := *t.modules.Load()
+	if _, ok := current[name]; !ok {
+		return
+	}
+	next := make(map[string]zapcore.Level, len(current))
+	for k, v := range current {
+		if k != name {
+			next[k] = v
+		}
+	}
+	t.modules.Store(&next)
+}
+
+// has 判断名称为 name 的日志记录器是否单独设置了级别。
+func (t *levelTree) has(name string) bool {
+	_, ok := (*t.modules.Load())[name]
+	return ok
+}
+
+// joinName 按 zap 的规则拼接日志记录器名称。
+func joinName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	return parent + "." + name
+}
+
+// levelTreeCore 在 zapcore.Core 之外按日志记录器名称过滤级别。
+// 由于只做过滤，它无法让内部核心输出低于其自身级别的日志。
+type levelTreeCore struct {
+	zapcore.Core
+	levels *levelTree
+}
+
+// Enabled 仅当级别树与内部核心都允许时才输出。
+func (c *levelTreeCore) Enabled(l zapcore.Level) bool {
+	return c.levels.Enabled(l) && c.Core.Enabled(l)
+}
+
+// With 返回附加了字段的新核心，并保留级别过滤。
+func (c *levelTreeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelTreeCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+// Check 先按日志记录器名称对应的级别过滤，再交由内部核心决定是否输出。
+func (c *levelTreeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.levels.levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
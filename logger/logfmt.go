@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtPool 为 logfmt 编码器提供输出缓冲区。
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder 以 logfmt（key=value）格式输出日志。
+// 它先借助 JSON 编码器完成字段编码，再按原有顺序转换为 logfmt，
+// 嵌套对象展开为以 "." 连接的键，数组保留为 JSON 文本。
+type logfmtEncoder struct {
+	zapcore.Encoder
+}
+
+// newLogfmtEncoder 使用 encoderConfig 创建 logfmt 编码器。
+func newLogfmtEncoder(encoderConfig zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{Encoder: zapcore.NewJSONEncoder(encoderConfig)}
+}
+
+// Clone 复制编码器及其已附加的字段。
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	return &logfmtEncoder{Encoder: enc.Encoder.Clone()}
+}
+
+// EncodeEntry 将日志条目编码为一行 logfmt。
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	jsonBuf, err := enc.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer jsonBuf.Free()
+
+	out := logfmtPool.Get()
+	decoder := json.NewDecoder(bytes.NewReader(jsonBuf.Bytes()))
+	decoder.UseNumber()
+	if err := writeLogfmtObject(out, decoder, ""); err != nil {
+		out.Free()
+		return nil, err
+	}
+	out.AppendByte('\n')
+	return out, nil
+}
+
+// writeLogfmtObject 读取 decoder 中的一个 JSON 对象，并以 prefix 为键前缀写入 out。
+func writeLogfmtObject(out *buffer.Buffer, decoder *json.Decoder, prefix string) error {
+	if _, err := decoder.Token(); err != nil { // 读取 '{'
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if len(raw) > 0 && raw[0] == '{' {
+			nested := json.NewDecoder(bytes.NewReader(raw))
+			nested.UseNumber()
+			if err := writeLogfmtObject(out, nested, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value := string(raw)
+		if len(raw) > 0 && raw[0] == '"' {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+		}
+		if out.Len() > 0 {
+			out.AppendByte(' ')
+		}
+		out.AppendString(key)
+		out.AppendByte('=')
+		out.AppendString(quoteLogfmt(value))
+	}
+	_, err := decoder.Token() // 读取 '}'
+	return err
+}
+
+// quoteLogfmt 在值为空或包含空白、引号、等号及控制字符时为其加上引号。
+func quoteLogfmt(value string) string {
+	if value == "" {
+		return `""`
+	}
+	needsQuote := strings.IndexFunc(value, func(r rune) bool {
+		return r == '"' || r == '=' || r == '\\' || unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0
+	if needsQuote {
+		return strconv.Quote(value)
+	}
+	return value
+}
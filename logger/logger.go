@@ -1,10 +1,14 @@
 package logger
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
-	"os"
 )
 
 /*
@@ -19,6 +23,12 @@ LogInterface 接口定义了一套日志操作方法，
   - Warn：记录警告信息，指出可能的问题但不影响当前执行流程。
   - Error：记录错误信息，指示发生了应当被关注并处理的错误情况。
   - Fatal：记录致命错误，并在执行该方法后终止程序运行。
+  - DebugCtx、InfoCtx、WarnCtx、ErrorCtx：与对应方法相同，并自动附加 context.Context 中的追踪ID与字段。
+  - Debugf 等以 f 结尾的方法：按 fmt.Sprintf 的格式生成日志消息。
+  - Debugw 等以 w 结尾的方法：以交替出现的键与值代替 zapcore.Field。
+  - With、Named：创建附加了字段或名称的子日志记录器。
+  - SetLogLevel、GetLogLevel：设置与读取日志级别。
+  - Sync、Close：写出缓冲中的日志，Close 还会停止异步输出并关闭日志文件，通常在程序退出前调用。
 
 参数说明：
   - msg：所有记录日志方法中的字符串参数，表示要记录的日志消息内容。
@@ -32,7 +42,32 @@ type LogInterface interface {
 	Warn(msg string, fields ...zapcore.Field)  // 记录警告日志。
 	Error(msg string, fields ...zapcore.Field) // 记录错误日志。
 	Fatal(msg string, fields ...zapcore.Field) // 记录致命错误日志后终止程序。
-	SetLogLevel(level string)                  // 设置日志级别。
+
+	DebugCtx(ctx context.Context, msg string, fields ...zapcore.Field) // 记录调试日志并附加上下文中的字段。
+	InfoCtx(ctx context.Context, msg string, fields ...zapcore.Field)  // 记录信息日志并附加上下文中的字段。
+	WarnCtx(ctx context.Context, msg string, fields ...zapcore.Field)  // 记录警告日志并附加上下文中的字段。
+	ErrorCtx(ctx context.Context, msg string, fields ...zapcore.Field) // 记录错误日志并附加上下文中的字段。
+
+	Debugf(template string, args ...interface{}) // 按格式记录调试日志。
+	Infof(template string, args ...interface{})  // 按格式记录信息日志。
+	Warnf(template string, args ...interface{})  // 按格式记录警告日志。
+	Errorf(template string, args ...interface{}) // 按格式记录错误日志。
+	Fatalf(template string, args ...interface{}) // 按格式记录致命错误日志后终止程序。
+
+	Debugw(msg string, keysAndValues ...interface{}) // 以键值对记录调试日志。
+	Infow(msg string, keysAndValues ...interface{})  // 以键值对记录信息日志。
+	Warnw(msg string, keysAndValues ...interface{})  // 以键值对记录警告日志。
+	Errorw(msg string, keysAndValues ...interface{}) // 以键值对记录错误日志。
+	Fatalw(msg string, keysAndValues ...interface{}) // 以键值对记录致命错误日志后终止程序。
+
+	With(fields ...zapcore.Field) LogInterface // 返回附加了字段的子日志记录器。
+	Named(name string) LogInterface            // 返回指定名称的子日志记录器，可单独设置级别。
+
+	SetLogLevel(level string) // 设置日志级别。
+	GetLogLevel() string      // 获取当前生效的日志级别。
+
+	Sync() error  // 将缓冲中的日志写出到全部输出。
+	Close() error // 写出缓冲中的日志并释放输出占用的资源。
 }
 
 /*
@@ -44,11 +79,53 @@ GLogger 结构体封装了日志功能，集成 zap.Logger 提供高性能日志
     提供了丰富的日志处理能力，如格式化、过滤和输出目标配置等。
   - LogLevel：日志级别枚举，来自 logger.LogLevel，用于设定日志输出的最低级别。
     允许动态调整以适应不同的运行环境（如生产、开发）对日志详略的需求。
+  - LogPath：第一个文件输出的路径。
+
+日志级别由所有输出与子日志记录器共享，LogLevel 仅记录最近一次设置的值，
+读取当前生效的级别请使用 GetLogLevel 方法。通过 Named 创建的子日志记录器可以单独设置级别。
+直接以 &GLogger{ZapLogger: ...} 构造的实例调用 SetLogLevel 后，级别只作用于 GLogger 的方法与其子日志记录器，
+ZapLogger 成员保持不变，以免与并发的日志记录产生数据竞争。
 */
 type GLogger struct {
 	ZapLogger *zap.Logger // zap 日志库的实例，负责实际的日志处理工作。
 	LogLevel  string      // 当前日志记录的最低级别门槛。
 	LogPath   string      // 日志路径
+
+	mu         sync.Mutex                    // 保护 LogLevel、LogPath 以及 compat、traceIDs 的写入。
+	name       string                        // 日志记录器名称，由 Named 逐级拼接，根日志记录器为空。
+	levels     *levelTree                    // 所有输出与子日志记录器共享的日志级别，直接构造的 GLogger 为 nil。
+	compat     atomic.Pointer[compatLevels]  // 直接构造的 GLogger 第一次调用 SetLogLevel 时创建的级别过滤。
+	core       *reloadableCore               // 可整体替换的日志核心，用于重新加载配置。
+	callerSkip int                           // 通过 WithCallerSkip 额外跳过的栈帧数。
+	traceIDs   atomic.Pointer[traceIDSource] // 所有子日志记录器共享的追踪ID生成器。
+
+	sugared atomic.Pointer[sugarCache] // 由 ZapLogger 创建的 zap.SugaredLogger 缓存。
+}
+
+// compatLevels 是直接构造的 GLogger 在原有核心外包装的可调整级别过滤。
+// 它以原子指针发布，使 SetLogLevel 不必修改 ZapLogger，从而不与并发的日志记录产生数据竞争。
+type compatLevels struct {
+	logger *zap.Logger
+	levels *levelTree
+}
+
+// base 返回记录日志使用的 zap.Logger，直接构造的 GLogger 调用过 SetLogLevel 后为包装了级别过滤的实例。
+func (log *GLogger) base() *zap.Logger {
+	if c := log.compat.Load(); c != nil {
+		return c.logger
+	}
+	return log.ZapLogger
+}
+
+// levelTree 返回日志级别，直接构造且从未调用过 SetLogLevel 的 GLogger 返回 nil。
+func (log *GLogger) levelTree() *levelTree {
+	if log.levels != nil {
+		return log.levels
+	}
+	if c := log.compat.Load(); c != nil {
+		return c.levels
+	}
+	return nil
 }
 
 // GetEncoder 创建并返回一个zapcore.Encoder，用于格式化日志输出至控制台。
@@ -78,83 +155,172 @@ func GetEncoder() zapcore.Encoder {
 }
 
 // GetFileLogWriter 根据给定的文件路径创建并返回一个实现了 zapcore.WriteSyncer 接口的对象，
-// 用于日志文件的写入与同步。使用 lumberjack 库来支持日志文件的滚动、压缩和清理。
+// 用于日志文件的写入与同步。通过 RotateFile 支持日志文件的切割、压缩和清理。
 //
 // @param logPath string: 日志文件的保存路径。
-// @return zapcore.WriteSyncer: 返回配置好的日志文件写入器。
+// @return zapcore.WriteSyncer: 返回使用默认切割策略的日志文件写入器。
 func GetFileLogWriter(logPath string) zapcore.WriteSyncer {
-	// lumberjack.Logger 配置：
-	// - Filename: 日志文件名
-	// - MaxSize: 单个日志文件最大大小，默认单位为MB
-	// - MaxBackups: 保留的旧日志文件的最大数量
-	// - MaxAge: 旧日志文件保留的最长时间，单位天
-	// - Compress: 是否启用日志文件压缩，默认不压缩
-	lumberJackLogger := &lumberjack.Logger{
-		Filename:   logPath,
-		MaxSize:    100,  // 修改为单个文件最大100M，原说明有误
-		MaxBackups: 60,   // 保留最多60个备份文件
-		MaxAge:     30,   // 修改为最多保留30天的日志文件，原说明有误
-		Compress:   true, // 压缩旧日志文件，提高存储效率
-	}
+	return GetRotateFileWriter(logPath, DefaultRotateConfig())
+}
 
-	// zapcore.AddSync 将 lumberjack.Logger 包装成 zapcore.WriteSyncer
-	return zapcore.AddSync(lumberJackLogger)
+// GetRotateFileWriter 与 GetFileLogWriter 相同，但使用调用方指定的切割策略，
+// 如按天切割、限制旧文件总大小、使用 zstd 压缩以及切割完成后的回调。
+//
+// @param logPath string: 日志文件的保存路径。
+// @param rotate RotateConfig: 日志文件的切割策略。
+// @return zapcore.WriteSyncer: 返回配置好的日志文件写入器，实际类型为 *RotateFile。
+func GetRotateFileWriter(logPath string, rotate RotateConfig) zapcore.WriteSyncer {
+	return NewRotateFile(logPath, rotate)
 }
 
 // Info 记录信息级别的日志。
 // @param msg string: 日志消息。
 // @param fields ...zapcore.Field: 额外的结构化日志字段。
 func (log *GLogger) Info(msg string, fields ...zapcore.Field) {
-	log.ZapLogger.Info(msg, fields...)
+	log.zapLogger(0).Info(msg, fields...)
 }
 
 // Debug 记录调试级别的日志。
 // @param msg string: 日志消息。
 // @param fields ...zapcore.Field: 额外的结构化日志字段。
 func (log *GLogger) Debug(msg string, fields ...zapcore.Field) {
-	log.ZapLogger.Debug(msg, fields...)
+	log.zapLogger(0).Debug(msg, fields...)
 }
 
 // Warn 记录警告级别的日志。
 // @param msg string: 日志消息。
 // @param fields ...zapcore.Field: 额外的结构化日志字段。
 func (log *GLogger) Warn(msg string, fields ...zapcore.Field) {
-	log.ZapLogger.Warn(msg, fields...)
+	log.zapLogger(0).Warn(msg, fields...)
 }
 
 // Error 记录错误级别的日志。
 // @param msg string: 日志消息。
 // @param fields ...zapcore.Field: 额外的结构化日志字段。
 func (log *GLogger) Error(msg string, fields ...zapcore.Field) {
-	log.ZapLogger.Error(msg, fields...)
+	log.zapLogger(0).Error(msg, fields...)
 }
 
 // Fatal 记录致命错误级别的日志，并在记录后调用 os.Exit(1) 终止程序。
 // @param msg string: 日志消息。
 // @param fields ...zapcore.Field: 额外的结构化日志字段。
 func (log *GLogger) Fatal(msg string, fields ...zapcore.Field) {
-	log.ZapLogger.Fatal(msg, fields...)
+	log.zapLogger(0).Fatal(msg, fields...)
 }
 
+// SetLogLevel 修改日志级别，修改立即作用于全部输出以及由该实例派生的子日志记录器。
+// 此方法不会重建日志核心，已有的写入器与字段保持不变，可在多个 goroutine 中并发调用。
+// 对 Named 创建的子日志记录器调用时只修改该名称及其下级名称的级别。
+// 也可以传入形如 "gorm=warn,http=info,*=debug" 的分模块配置，模块名相对于当前日志记录器，
+// "*" 表示当前日志记录器本身。
+// @param level string: 新的日志级别，大小写不敏感。无法识别时记录一条错误日志，并保持原有级别不变。
 func (log *GLogger) SetLogLevel(level string) {
+	if _, _, err := parseLevelSpec(level); err != nil {
+		log.zapLogger(0).Error("[SetLogLevel] invalid level, keeping previous level", zap.String("level", level), zap.Error(err))
+		return
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
 	log.LogLevel = level
+	levels := log.levelTree()
+	if levels == nil {
+		// 兼容直接构造的 GLogger：在原有核心外包装一层可调整的级别过滤
+		levels = newLevelTree(zapcore.InfoLevel)
+		log.compat.Store(&compatLevels{
+			logger: log.ZapLogger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+				return &levelTreeCore{Core: core, levels: levels}
+			})),
+			levels: levels,
+		})
+	}
+	_ = levels.set(log.name, level)
+}
+
+// GetLogLevel 返回当前生效的日志级别，如 "debug"、"info"。
+// @return string: 当前日志级别的小写字符串表示。
+func (log *GLogger) GetLogLevel() string {
+	log.mu.Lock()
+	defer log.mu.Unlock()
 
-	// 从配置中获取日志级别和路径信息
-	logLevel := GetLogLevel(log.LogLevel)
-	logPath := log.LogPath
+	levels := log.levelTree()
+	if levels == nil {
+		return GetLogLevel(log.LogLevel).String()
+	}
+	return levels.levelFor(log.name).String()
+}
 
-	// 配置日志编码器，用于格式化输出到控制台的日志
-	encoder := GetEncoder()
+// InheritsLogLevel 判断该日志记录器是否沿用上级名称或全局的级别，即未通过 SetLogLevel 单独设置级别。
+// 根日志记录器的级别即全局级别，总是返回 false。
+// @return bool: 沿用上级级别时返回 true。
+func (log *GLogger) InheritsLogLevel() bool {
+	levels := log.levelTree()
+	return log.name != "" && levels != nil && !levels.has(log.name)
+}
 
-	// 准备文件写入器，用于将日志记录到指定文件
-	fileWriteSyncer := GetFileLogWriter(logPath)
+// ResetLogLevel 删除通过 SetLogLevel 为该日志记录器单独设置的级别，之后重新沿用上级名称或全局的级别，
+// 下级名称单独设置的级别保持不变。对根日志记录器调用时不做任何事。
+func (log *GLogger) ResetLogLevel() {
+	log.mu.Lock()
+	defer log.mu.Unlock()
 
-	// 设置日志核心，允许同时输出到控制台和文件，根据环境调整此逻辑
-	core := zapcore.NewTee(
-		// 注意：生产环境中应考虑移除或调整控制台输出
-		zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), logLevel),
-		zapcore.NewCore(encoder, fileWriteSyncer, logLevel),
-	)
+	if levels := log.levelTree(); log.name != "" && levels != nil {
+		levels.unset(log.name)
+	}
+}
 
-	log.ZapLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2))
+// With 返回附加了字段的子日志记录器，子日志记录器与原实例共享日志级别与输出。
+// @param fields ...zapcore.Field: 附加到子日志记录器每条日志上的字段。
+// @return LogInterface: 子日志记录器。
+func (log *GLogger) With(fields ...zapcore.Field) LogInterface {
+	return log.derive(log.base().With(fields...))
+}
+
+// Named 返回指定名称的子日志记录器，名称按 "." 逐级拼接，如 "gorm.query"。
+// 子日志记录器可通过 SetLogLevel 单独设置级别，未设置时沿用上级名称的级别。
+// @param name string: 子日志记录器名称。
+// @return LogInterface: 子日志记录器。
+func (log *GLogger) Named(name string) LogInterface {
+	child := log.derive(log.base().Named(name))
+	child.name = joinName(log.name, name)
+	return child
+}
+
+// Sync 将缓冲中的日志写出到全部输出，异步输出会等待队列中的日志全部写出。
+// 标准输出与标准错误指向终端或管道时不支持 Sync，此类错误会被忽略。
+// @return error: 写出或刷新失败时返回的错误。
+func (log *GLogger) Sync() error {
+	return ignoreSyncErrors(log.base().Sync())
+}
+
+// Close 写出缓冲中的日志，停止异步输出的后台 goroutine 并关闭日志文件。
+// 子日志记录器与原实例共享输出，对任意一个调用 Close 都会关闭全部输出，
+// 因此通常只在程序退出前对根日志记录器调用一次。关闭后仍可继续记录日志，
+// 异步输出将改为同步写出，日志文件会在下次写入时重新打开。
+// @return error: 写出或关闭失败时返回的错误。
+func (log *GLogger) Close() error {
+	err := log.Sync()
+	if log.core == nil {
+		return err
+	}
+	return errors.Join(err, ignoreSyncErrors(log.core.sinks().close()))
+}
+
+// ignoreSyncErrors 去掉 err 中因输出不支持 Sync（如终端、管道）而产生的错误。
+func ignoreSyncErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range multi.Unwrap() {
+			errs = append(errs, ignoreSyncErrors(e))
+		}
+		return errors.Join(errs...)
+	}
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logrSink 是基于 GLogger 的 logr.LogSink。
+type logrSink struct {
+	log   *GLogger
+	depth int // 除 logr.Logger 自身外，调用方额外包装的栈帧数。
+	info  logr.RuntimeInfo
+	zl    *zap.Logger // 跳过了 logr.Logger 及调用方包装栈帧的 zap.Logger。
+}
+
+// NewLogrSink 返回写入 log 的 logr.LogSink，供使用 go-logr 的第三方库接入。
+// logr 的 V(0) 对应 info 级别，V(1) 及以上对应 debug 级别；WithName 等价于 GLogger.Named，
+// WithValues 中的键值对转换为日志字段，也可以直接传入 zap.Field。
+//
+// 示例：
+//
+//	ctrl.SetLogger(logr.New(logger.NewLogrSink(monophonic.Default())))
+func NewLogrSink(log *GLogger) logr.LogSink {
+	return newLogrSink(log, 0, logr.RuntimeInfo{})
+}
+
+// newLogrSink 创建 logrSink 并计算需要跳过的栈帧数。
+func newLogrSink(log *GLogger, depth int, info logr.RuntimeInfo) *logrSink {
+	return &logrSink{
+		log:   log,
+		depth: depth,
+		info:  info,
+		zl:    log.base().WithOptions(zap.AddCallerSkip(info.CallDepth + depth)),
+	}
+}
+
+// Init 记录 logr.Logger 包装的栈帧数。
+func (s *logrSink) Init(info logr.RuntimeInfo) {
+	*s = *newLogrSink(s.log, s.depth, info)
+}
+
+// Enabled 判断 V(level) 的日志是否会输出，同时考虑按名称设置的级别。
+func (s *logrSink) Enabled(level int) bool {
+	l := zapLevelFromLogr(level)
+	if !s.log.base().Core().Enabled(l) {
+		return false
+	}
+	levels := s.log.levelTree()
+	return levels == nil || l >= levels.levelFor(s.log.name)
+}
+
+// Info 记录 V(level) 的日志。
+func (s *logrSink) Info(level int, msg string, keysAndValues ...any) {
+	if ce := s.zl.Check(zapLevelFromLogr(level), msg); ce != nil {
+		ce.Write(keysAndValuesToFields(keysAndValues)...)
+	}
+}
+
+// Error 记录错误级别的日志，err 以 error 字段输出。
+func (s *logrSink) Error(err error, msg string, keysAndValues ...any) {
+	if ce := s.zl.Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(append([]zapcore.Field{zap.Error(err)}, keysAndValuesToFields(keysAndValues)...)...)
+	}
+}
+
+// WithValues 返回附加了键值对的新 LogSink。
+func (s *logrSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return newLogrSink(s.log.derive(s.log.base().With(keysAndValuesToFields(keysAndValues)...)), s.depth, s.info)
+}
+
+// WithName 返回指定名称的新 LogSink，名称按 "." 拼接。
+func (s *logrSink) WithName(name string) logr.LogSink {
+	return newLogrSink(s.log.Named(name).(*GLogger), s.depth, s.info)
+}
+
+// WithCallDepth 返回额外跳过 depth 个栈帧的新 LogSink，实现 logr.CallDepthLogSink。
+func (s *logrSink) WithCallDepth(depth int) logr.LogSink {
+	return newLogrSink(s.log, s.depth+depth, s.info)
+}
+
+// zapLevelFromLogr 将 logr 的 V 级别转换为 zap 级别。
+func zapLevelFromLogr(level int) zapcore.Level {
+	if level > 0 {
+		return zapcore.DebugLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// keysAndValuesToFields 将交替出现的键值对转换为日志字段，zap.Field 可直接出现在其中。
+// 非字符串的键按 fmt.Sprint 转换，缺少值的键以 "(MISSING)" 作为值。
+func keysAndValuesToFields(keysAndValues []any) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); {
+		if field, ok := keysAndValues[i].(zapcore.Field); ok {
+			fields = append(fields, field)
+			i++
+			continue
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		if i+1 >= len(keysAndValues) {
+			fields = append(fields, zap.String(key, "(MISSING)"))
+			break
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		i += 2
+	}
+	return fields
+}
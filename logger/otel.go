@@ -0,0 +1,271 @@
+package logger
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OpenTelemetry 日志数据模型中关联追踪的字段名，ctx 中存在有效的 OpenTelemetry span 时由 FieldsFromContext 附加。
+const (
+	OTelTraceIDKey    = "trace_id"
+	OTelSpanIDKey     = "span_id"
+	OTelTraceFlagsKey = "trace_flags"
+)
+
+// OTelScopeName 是 SinkOTel 输出未命名日志记录器的日志时使用的 instrumentation scope 名称，
+// 命名的日志记录器（如 "gorm"、"http"）以自身名称作为 scope。
+const OTelScopeName = "github.com/uniharmonic/monophonic"
+
+// spanContextFields 返回 ctx 中 OpenTelemetry span 的 trace_id、span_id 与 trace_flags 字段，
+// span 不存在或无效时返回 nil。
+func spanContextFields(ctx context.Context) []zapcore.Field {
+	if ctx == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zapcore.Field{
+		zap.String(OTelTraceIDKey, sc.TraceID().String()),
+		zap.String(OTelSpanIDKey, sc.SpanID().String()),
+		zap.String(OTelTraceFlagsKey, sc.TraceFlags().String()),
+	}
+}
+
+// otelSpan 是从日志字段中还原的 span 信息，用于在输出时重新关联到追踪。
+type otelSpan struct {
+	traceID, spanID, flags string
+}
+
+// set 记录 key 对应的 span 信息，key 不是关联追踪的字段时返回 false。
+func (s *otelSpan) set(f zapcore.Field) bool {
+	if f.Type != zapcore.StringType {
+		return false
+	}
+	switch f.Key {
+	case OTelTraceIDKey:
+		s.traceID = f.String
+	case OTelSpanIDKey:
+		s.spanID = f.String
+	case OTelTraceFlagsKey:
+		s.flags = f.String
+	default:
+		return false
+	}
+	return true
+}
+
+// context 返回携带该 span 的 context.Context，span 信息不完整或不合法时不携带。
+func (s otelSpan) context() context.Context {
+	ctx := context.Background()
+	traceID, err := trace.TraceIDFromHex(s.traceID)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(s.spanID)
+	if err != nil {
+		return ctx
+	}
+	var flags trace.TraceFlags
+	if b, err := hex.DecodeString(s.flags); err == nil && len(b) == 1 {
+		flags = trace.TraceFlags(b[0])
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: flags, Remote: true})
+	return trace.ContextWithSpanContext(ctx, sc)
+}
+
+// otelCore 是通过 OpenTelemetry 日志桥接输出日志的 zapcore.Core。
+// 日志字段转换为日志记录的属性，trace_id、span_id 与 trace_flags 字段转换为日志记录关联的 span。
+type otelCore struct {
+	zapcore.LevelEnabler
+	provider log.LoggerProvider
+	loggers  *sync.Map // 日志记录器名称 → log.Logger，在 With 创建的副本之间共享。
+	attrs    []log.KeyValue
+	span     otelSpan
+}
+
+// newOTelCore 创建输出到 provider 的 zapcore.Core，provider 为 nil 时使用全局的 LoggerProvider。
+func newOTelCore(provider log.LoggerProvider, enabler zapcore.LevelEnabler) zapcore.Core {
+	if provider == nil {
+		provider = global.GetLoggerProvider()
+	}
+	return &otelCore{LevelEnabler: enabler, provider: provider, loggers: &sync.Map{}}
+}
+
+// With 返回附加了 fields 的副本。
+func (c *otelCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.attrs, clone.span = c.convert(fields)
+	return &clone
+}
+
+// Check 在级别满足时将自身加入 ce。
+func (c *otelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 将日志转换为 OpenTelemetry 日志记录并输出。
+func (c *otelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var record log.Record
+	record.SetTimestamp(ent.Time)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(otelSeverity(ent.Level))
+	record.SetSeverityText(ent.Level.String())
+	record.SetBody(log.StringValue(ent.Message))
+
+	attrs, span := c.convert(fields)
+	record.AddAttributes(attrs...)
+	if ent.Caller.Defined {
+		record.AddAttributes(
+			log.String("code.filepath", ent.Caller.File),
+			log.Int("code.lineno", ent.Caller.Line),
+			log.String("code.function", ent.Caller.Function),
+		)
+	}
+	if ent.Stack != "" {
+		record.AddAttributes(log.String("code.stacktrace", ent.Stack))
+	}
+	c.logger(ent.LoggerName).Emit(span.context(), record)
+	return nil
+}
+
+// Sync 没有需要写出的缓冲，导出由 LoggerProvider 负责。
+func (c *otelCore) Sync() error {
+	return nil
+}
+
+// logger 返回 name 对应的 log.Logger，未命名的日志记录器使用 OTelScopeName。
+func (c *otelCore) logger(name string) log.Logger {
+	if name == "" {
+		name = OTelScopeName
+	}
+	if l, ok := c.loggers.Load(name); ok {
+		return l.(log.Logger)
+	}
+	l, _ := c.loggers.LoadOrStore(name, c.provider.Logger(name))
+	return l.(log.Logger)
+}
+
+// convert 将 fields 追加到已有的属性之后，并从中取出关联追踪的字段。
+func (c *otelCore) convert(fields []zapcore.Field) ([]log.KeyValue, otelSpan) {
+	attrs := c.attrs[:len(c.attrs):len(c.attrs)]
+	span := c.span
+	enc := zapcore.NewMapObjectEncoder()
+	var keys []string
+	for _, f := range fields {
+		if span.set(f) {
+			continue
+		}
+		if _, ok := enc.Fields[f.Key]; !ok {
+			keys = append(keys, f.Key)
+		}
+		f.AddTo(enc)
+	}
+	for _, key := range keys {
+		if v, ok := enc.Fields[key]; ok {
+			attrs = append(attrs, log.KeyValue{Key: key, Value: otelValue(v)})
+		}
+	}
+	return attrs, span
+}
+
+// otelValue 将 zapcore.MapObjectEncoder 编码得到的值转换为 log.Value。
+func otelValue(v any) log.Value {
+	switch v := v.(type) {
+	case nil:
+		return log.Value{}
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int64:
+		return log.Int64Value(v)
+	case int32:
+		return log.Int64Value(int64(v))
+	case int16:
+		return log.Int64Value(int64(v))
+	case int8:
+		return log.Int64Value(int64(v))
+	case int:
+		return log.Int64Value(int64(v))
+	case uint64:
+		if v > math.MaxInt64 {
+			return log.StringValue(fmt.Sprint(v))
+		}
+		return log.Int64Value(int64(v))
+	case uint32:
+		return log.Int64Value(int64(v))
+	case uint16:
+		return log.Int64Value(int64(v))
+	case uint8:
+		return log.Int64Value(int64(v))
+	case uintptr:
+		return log.Int64Value(int64(v))
+	case float64:
+		return log.Float64Value(v)
+	case float32:
+		return log.Float64Value(float64(v))
+	case []byte:
+		return log.BytesValue(v)
+	case time.Duration:
+		return log.StringValue(v.String())
+	case time.Time:
+		return log.StringValue(v.Format(time.RFC3339Nano))
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		kvs := make([]log.KeyValue, 0, len(v))
+		for _, key := range keys {
+			kvs = append(kvs, log.KeyValue{Key: key, Value: otelValue(v[key])})
+		}
+		return log.MapValue(kvs...)
+	case []any:
+		values := make([]log.Value, 0, len(v))
+		for _, item := range v {
+			values = append(values, otelValue(item))
+		}
+		return log.SliceValue(values...)
+	default:
+		return log.StringValue(fmt.Sprintf("%+v", v))
+	}
+}
+
+// otelSeverity 将 zap 的日志级别映射为 OpenTelemetry 的 Severity。
+func otelSeverity(level zapcore.Level) log.Severity {
+	switch level {
+	case zapcore.DebugLevel:
+		return log.SeverityDebug
+	case zapcore.InfoLevel:
+		return log.SeverityInfo
+	case zapcore.WarnLevel:
+		return log.SeverityWarn
+	case zapcore.ErrorLevel:
+		return log.SeverityError
+	case zapcore.DPanicLevel:
+		return log.SeverityFatal1
+	case zapcore.PanicLevel:
+		return log.SeverityFatal2
+	case zapcore.FatalLevel:
+		return log.SeverityFatal3
+	default:
+		return log.SeverityUndefined
+	}
+}
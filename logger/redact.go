@@ -0,0 +1,391 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRedactMask 是脱敏后替换敏感内容的默认文本。
+const DefaultRedactMask = "***"
+
+// 以下正则表达式用于在自由文本中识别常见的敏感信息，可用于 RedactConfig.Patterns。
+const (
+	RedactPatternEmail  = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}` // 电子邮箱。
+	RedactPatternPhone  = `\b1[3-9]\d{9}\b`                                // 中国大陆手机号。
+	RedactPatternIDCard = `\b\d{17}[\dXx]\b`                               // 中国大陆居民身份证号。
+	RedactPatternCard   = `\b\d(?:[ -]?\d){12,18}\b`                       // 13至19位且通过 Luhn 校验的银行卡号，允许以空格或 "-" 分组。
+)
+
+// redactExemptKeys 是不按 Patterns 脱敏的追踪相关字段，其值为ID，容易被误判为卡号等敏感内容。
+var redactExemptKeys = map[string]bool{
+	TraceIDKey:        true,
+	"requestId":       true,
+	"spanId":          true,
+	"parentSpanId":    true,
+	OTelTraceIDKey:    true,
+	OTelSpanIDKey:     true,
+	OTelTraceFlagsKey: true,
+}
+
+/*
+RedactConfig 描述日志脱敏规则，规则作用于全部输出，包括日志消息与所有字段。
+
+属性说明：
+  - Keys：敏感字段的键名。比较时忽略大小写以及 "-"、"_"，键名以其中任意一项结尾即视为敏感，
+    如 "token" 同时匹配 "access_token" 与 "X-Csrf-Token"。匹配的字段整体替换为 Mask，
+    嵌套对象、JSON 文本中的同名键同样生效；自由文本中形如 "password=123"、"token: abc"、"`token`='abc'" 的内容只替换值。
+  - Patterns：在字符串值与日志消息中匹配敏感内容的正则表达式，匹配部分替换为 Mask。
+    traceId、spanId 等追踪字段不按 Patterns 脱敏；RedactPatternCard 匹配的数字还需通过 Luhn 校验才会替换。
+  - JSONPaths：以 "." 分隔、从字段名开始的路径，如 "query.user.password"、"$.body.cards[*].number"，
+    "*" 匹配任意键或数组下标。路径可以深入对象类型的字段以及内容为 JSON 的字符串字段。
+  - Mask：替换文本，为空时使用 DefaultRedactMask。
+*/
+type RedactConfig struct {
+	Keys      []string
+	Patterns  []string
+	JSONPaths []string
+	Mask      string
+}
+
+// DefaultRedactConfig 返回默认的脱敏规则：常见的密码、令牌、认证与 Cookie 字段，
+// 以及自由文本中的银行卡号、身份证号、手机号与电子邮箱。
+func DefaultRedactConfig() RedactConfig {
+	return RedactConfig{
+		Keys:     []string{"password", "passwd", "pwd", "secret", "token", "authorization", "cookie", "apikey"},
+		Patterns: []string{RedactPatternCard, RedactPatternIDCard, RedactPatternPhone, RedactPatternEmail},
+		Mask:     DefaultRedactMask,
+	}
+}
+
+// redactor 是编译后的脱敏规则。
+type redactor struct {
+	keys     []string        // 规范化后的敏感键名。
+	keyValue *regexp.Regexp  // 匹配自由文本中 "key=value" 形式的敏感内容，没有键名时为 nil。
+	patterns []redactPattern // 匹配敏感内容的正则表达式。
+	paths    [][]string      // 拆分后的 JSON 路径。
+	mask     string
+}
+
+// redactPattern 是编译后的正则表达式，valid 不为 nil 时只替换通过校验的匹配。
+type redactPattern struct {
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// newRedactor 编译脱敏规则，正则表达式或路径不合法时返回错误。
+func newRedactor(cfg RedactConfig) (*redactor, error) {
+	r := &redactor{mask: cfg.Mask}
+	if r.mask == "" {
+		r.mask = DefaultRedactMask
+	}
+
+	quoted := make([]string, 0, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		if normalized := normalizeRedactKey(key); normalized != "" {
+			r.keys = append(r.keys, normalized)
+			quoted = append(quoted, regexp.QuoteMeta(key))
+		}
+	}
+	if len(quoted) > 0 {
+		// 键名前允许出现其他单词字符，与 Keys 的后缀匹配规则保持一致
+		r.keyValue = regexp.MustCompile(`(?i)([\w-]*(?:` + strings.Join(quoted, "|") + `)["'` + "`" + `]?\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s&,;"']+)`)
+	}
+
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("logger: invalid redact pattern %q: %w", pattern, err)
+		}
+		p := redactPattern{re: re}
+		if pattern == RedactPatternCard {
+			p.valid = luhnValid
+		}
+		r.patterns = append(r.patterns, p)
+	}
+
+	for _, path := range cfg.JSONPaths {
+		segments, err := splitJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, segments)
+	}
+	return r, nil
+}
+
+// luhnValid 判断以空格或 "-" 分组的数字是否通过 Luhn 校验。
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// normalizeRedactKey 将键名转换为小写并去掉 "-" 与 "_"。
+func normalizeRedactKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+}
+
+// splitJSONPath 将 "$.a.b[0]" 形式的路径拆分为 ["a", "b", "0"]。
+func splitJSONPath(path string) ([]string, error) {
+	p := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	p = strings.NewReplacer("[", ".", "]", "").Replace(p)
+	segments := strings.Split(p, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("logger: invalid redact json path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// sensitiveKey 判断键名是否命中 Keys。
+func (r *redactor) sensitiveKey(key string) bool {
+	normalized := normalizeRedactKey(key)
+	for _, k := range r.keys {
+		if strings.HasSuffix(normalized, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath 判断 path 是否命中 JSONPaths 中的某一条。
+func (r *redactor) matchPath(path []string) bool {
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		matched := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactText 替换自由文本中的敏感内容，返回替换后的文本以及是否发生替换。
+func (r *redactor) redactText(s string) (string, bool) {
+	out := s
+	if r.keyValue != nil {
+		out = r.keyValue.ReplaceAllString(out, "${1}"+strings.ReplaceAll(r.mask, "$", "$$"))
+	}
+	for _, p := range r.patterns {
+		if p.valid == nil {
+			out = p.re.ReplaceAllLiteralString(out, r.mask)
+			continue
+		}
+		out = p.re.ReplaceAllStringFunc(out, func(match string) string {
+			if p.valid(match) {
+				return r.mask
+			}
+			return match
+		})
+	}
+	return out, out != s
+}
+
+// redactString 处理字符串值：内容为 JSON 时按键名与路径逐项脱敏，否则按自由文本处理。
+func (r *redactor) redactString(path []string, s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) > 1 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		decoder := json.NewDecoder(strings.NewReader(trimmed))
+		decoder.UseNumber()
+		var tree any
+		if err := decoder.Decode(&tree); err == nil && !decoder.More() {
+			tree, changed := r.redactValue(path, tree)
+			if !changed {
+				return s, false
+			}
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(tree); err == nil {
+				return strings.TrimSuffix(buf.String(), "\n"), true
+			}
+		}
+	}
+	return r.redactText(s)
+}
+
+// redactValue 递归处理由对象、数组与基本类型组成的值。
+func (r *redactor) redactValue(path []string, v any) (any, bool) {
+	if r.matchPath(path) {
+		return r.mask, true
+	}
+	switch value := v.(type) {
+	case map[string]any:
+		changed := false
+		for k, child := range value {
+			if r.sensitiveKey(k) {
+				value[k], changed = r.mask, true
+				continue
+			}
+			if redacted, ok := r.redactValue(append(path[:len(path):len(path)], k), child); ok {
+				value[k], changed = redacted, true
+			}
+		}
+		return value, changed
+	case []any:
+		changed := false
+		for i, child := range value {
+			if redacted, ok := r.redactValue(append(path[:len(path):len(path)], strconv.Itoa(i)), child); ok {
+				value[i], changed = redacted, true
+			}
+		}
+		return value, changed
+	case string:
+		return r.redactString(path, value)
+	default:
+		return v, false
+	}
+}
+
+// redactFields 返回脱敏后的字段，没有字段需要修改时返回原切片。
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	var namespace []string
+	for i, f := range fields {
+		redacted, changed := r.redactField(namespace, f)
+		if f.Type == zapcore.NamespaceType {
+			namespace = append(namespace[:len(namespace):len(namespace)], f.Key)
+		}
+		if changed && out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields)
+		}
+		if out != nil {
+			out[i] = redacted
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// redactField 按键名、路径与正则表达式处理单个字段。
+func (r *redactor) redactField(namespace []string, f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.NamespaceType, zapcore.SkipType:
+		return f, false
+	}
+	path := append(namespace[:len(namespace):len(namespace)], f.Key)
+	if r.sensitiveKey(f.Key) || r.matchPath(path) {
+		return zap.String(f.Key, r.mask), true
+	}
+	if redactExemptKeys[f.Key] {
+		return f, false
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		if s, ok := r.redactString(path, f.String); ok {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ByteStringType:
+		if s, ok := r.redactString(path, string(f.Interface.([]byte))); ok {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.StringerType:
+		if s, ok := r.redactString(path, stringerValue(f.Interface.(fmt.Stringer))); ok {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ErrorType:
+		if err, _ := f.Interface.(error); err != nil {
+			if s, ok := r.redactText(err.Error()); ok {
+				return zap.NamedError(f.Key, errors.New(s)), true
+			}
+		}
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.ReflectType:
+		if tree, ok := fieldTree(f); ok {
+			if redacted, changed := r.redactValue(path, tree); changed {
+				return zap.Any(f.Key, redacted), true
+			}
+		}
+	}
+	return f, false
+}
+
+// stringerValue 调用 String 方法，方法发生 panic 时返回空字符串，交由 zap 按原方式处理。
+func stringerValue(s fmt.Stringer) (str string) {
+	defer func() {
+		if recover() != nil {
+			str = ""
+		}
+	}()
+	return s.String()
+}
+
+// fieldTree 将对象类字段转换为由 map、切片与基本类型组成的值，便于逐项脱敏。
+func fieldTree(f zapcore.Field) (any, bool) {
+	if f.Type == zapcore.ReflectType {
+		data, err := json.Marshal(f.Interface)
+		if err != nil {
+			return nil, false
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var tree any
+		if err := decoder.Decode(&tree); err != nil {
+			return nil, false
+		}
+		return tree, true
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	tree, ok := enc.Fields[f.Key]
+	return tree, ok
+}
+
+// redactCore 在写入前按脱敏规则处理日志消息与字段。
+type redactCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+// With 在附加字段前先对字段脱敏。
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.r.redactFields(fields)), r: c.r}
+}
+
+// Check 在级别允许时将自身加入待写入的核心列表，以便写入前完成脱敏。
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 对日志消息与字段脱敏后写入内部核心。
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message, _ = c.r.redactText(ent.Message)
+	return c.Core.Write(ent, c.r.redactFields(fields))
+}
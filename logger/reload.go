@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// reloadableCore 将实际的 zapcore.Core 放在一个可整体替换的指针之后，
+// 使 GLogger.Reload 替换输出后，已经派生的子日志记录器也会写入新的输出。
+type reloadableCore struct {
+	root   *atomic.Pointer[coreState]  // 所有派生核心共享的当前核心。
+	fields []zapcore.Field             // 通过 With 附加、需要在当前核心上重放的字段。
+	cache  atomic.Pointer[derivedCore] // 在当前核心上附加 fields 后的缓存结果。
+}
+
+// coreState 是 buildCore 创建的核心及其需要在替换后释放的输出资源。
+type coreState struct {
+	core  zapcore.Core
+	sinks *sinkSet
+}
+
+// derivedCore 记录由某个根核心附加字段后得到的核心。
+type derivedCore struct {
+	base *coreState
+	core zapcore.Core
+}
+
+// newReloadableCore 以 core 作为初始核心创建 reloadableCore。
+func newReloadableCore(core zapcore.Core, sinks *sinkSet) *reloadableCore {
+	root := new(atomic.Pointer[coreState])
+	root.Store(&coreState{core: core, sinks: sinks})
+	return &reloadableCore{root: root}
+}
+
+// swap 替换所有派生核心共享的当前核心，并返回被替换的输出资源。
+func (c *reloadableCore) swap(core zapcore.Core, sinks *sinkSet) *sinkSet {
+	return c.root.Swap(&coreState{core: core, sinks: sinks}).sinks
+}
+
+// sinks 返回当前核心的输出资源。
+func (c *reloadableCore) sinks() *sinkSet {
+	return c.root.Load().sinks
+}
+
+// current 返回附加了 fields 的当前核心，根核心未变化时复用缓存。
+func (c *reloadableCore) current() zapcore.Core {
+	base := c.root.Load()
+	if len(c.fields) == 0 {
+		return base.core
+	}
+	if d := c.cache.Load(); d != nil && d.base == base {
+		return d.core
+	}
+	core := base.core.With(c.fields)
+	c.cache.Store(&derivedCore{base: base, core: core})
+	return core
+}
+
+// Enabled 交由当前核心判断。
+func (c *reloadableCore) Enabled(l zapcore.Level) bool {
+	return c.current().Enabled(l)
+}
+
+// With 返回附加了字段的新核心，字段会在每次替换后重新附加到新的核心上。
+func (c *reloadableCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &reloadableCore{root: c.root, fields: merged}
+}
+
+// Check 交由当前核心决定是否输出，输出时直接写入当前核心。
+func (c *reloadableCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.current().Check(ent, ce)
+}
+
+// Write 写入当前核心。
+func (c *reloadableCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(ent, fields)
+}
+
+// Sync 刷新当前核心。
+func (c *reloadableCore) Sync() error {
+	return c.current().Sync()
+}
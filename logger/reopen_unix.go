@@ -0,0 +1,35 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// notifyReopen 在收到 SIGHUP 时重新打开 set 中的日志文件，返回停止监听的函数。
+func notifyReopen(set *sinkSet) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-signals:
+				// 打开失败时继续写入原来的文件，文件被移动或删除时下次写入前还会再次尝试
+				_ = set.reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
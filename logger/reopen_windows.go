@@ -0,0 +1,8 @@
+//go:build windows
+
+package logger
+
+// notifyReopen 在 Windows 上没有 SIGHUP，只能通过 GLogger.Reopen 重新打开日志文件。
+func notifyReopen(set *sinkSet) func() {
+	return nil
+}
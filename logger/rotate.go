@@ -0,0 +1,550 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// RotateInterval 表示按时间切割日志文件的周期。
+type RotateInterval string
+
+const (
+	// RotateNever 不按时间切割，只按大小切割。
+	RotateNever RotateInterval = ""
+	// RotateHourly 每小时切割一次，旧文件名形如 run-2006-01-02T15.log。
+	RotateHourly RotateInterval = "hourly"
+	// RotateDaily 每天切割一次，旧文件名形如 run-2006-01-02.log。
+	RotateDaily RotateInterval = "daily"
+)
+
+// Compression 表示旧日志文件的压缩算法。
+type Compression string
+
+const (
+	// CompressionNone 不压缩旧日志文件。
+	CompressionNone Compression = "none"
+	// CompressionGzip 使用 gzip 压缩，文件名追加 ".gz"。
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd 使用 zstd 压缩，文件名追加 ".zst"。
+	CompressionZstd Compression = "zstd"
+)
+
+// RotateHook 在旧日志文件切割（以及压缩）完成后调用，path 为旧文件最终的路径，可用于归档。
+type RotateHook func(path string)
+
+const (
+	megabyte = 1 << 20
+	// defaultMaxSize 是 MaxSize 为0时使用的单个文件大小上限，单位为MB，与 lumberjack 一致。
+	defaultMaxSize = 100
+	// defaultExternalCheckInterval 是 ExternalCheckInterval 不大于0时检查文件是否被移动或删除的间隔。
+	defaultExternalCheckInterval = time.Second
+	// sizeBackupLayout 是只按大小切割时旧文件名中的时间格式，与 lumberjack 一致，可以识别之前留下的旧文件。
+	sizeBackupLayout = "2006-01-02T15-04-05.000"
+	hourlyLayout     = "2006-01-02T15"
+	dailyLayout      = "2006-01-02"
+)
+
+// validate 检查切割策略中的取值是否合法。
+func (r RotateConfig) validate() error {
+	switch r.Interval {
+	case RotateNever, RotateHourly, RotateDaily:
+	default:
+		return fmt.Errorf("logger: unknown rotate interval %q", r.Interval)
+	}
+	switch r.Compression {
+	case "", CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return fmt.Errorf("logger: unknown compression %q", r.Compression)
+	}
+	if r.MaxBackups < 0 || r.MaxAge < 0 || r.MaxTotalSize < 0 {
+		return fmt.Errorf("logger: max_backups, max_age and max_total_size must not be negative")
+	}
+	return nil
+}
+
+// compression 返回实际使用的压缩算法，Compression 为空时由 Compress 决定。
+func (r RotateConfig) compression() Compression {
+	switch {
+	case r.Compression != "":
+		return r.Compression
+	case r.Compress:
+		return CompressionGzip
+	default:
+		return CompressionNone
+	}
+}
+
+// maxBytes 返回单个文件的大小上限，不按大小切割时返回0。
+func (r RotateConfig) maxBytes() int64 {
+	switch {
+	case r.MaxSize < 0:
+		return 0
+	case r.MaxSize == 0:
+		return defaultMaxSize * megabyte
+	default:
+		return int64(r.MaxSize) * megabyte
+	}
+}
+
+// externalCheckInterval 返回外部切割模式下检查文件是否被移动或删除的间隔。
+func (r RotateConfig) externalCheckInterval() time.Duration {
+	if r.ExternalCheckInterval <= 0 {
+		return defaultExternalCheckInterval
+	}
+	return r.ExternalCheckInterval
+}
+
+// periodStart 返回 t 所在切割周期的开始时间，不按时间切割时返回零值。
+func (r RotateConfig) periodStart(t time.Time) time.Time {
+	switch r.Interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+/*
+RotateFile 是按大小与时间切割的日志文件，实现了 zapcore.WriteSyncer，可以在多个 goroutine 中并发使用。
+
+当前文件始终位于创建时指定的路径，切割时将其重命名为带时间的旧文件：只按大小切割时形如
+run-2006-01-02T15-04-05.000.log；按时间切割时为文件所属的周期，如 run-2006-01-02.log，
+同一周期内因大小再次切割时追加序号，如 run-2006-01-02.1.log。
+旧文件在后台压缩，之后依次调用 RotateHook，最后按 MaxBackups、MaxAge 与 MaxTotalSize 清理更早的旧文件。
+
+RotateConfig.External 为 true 时由外部工具（如 logrotate）负责切割：RotateFile 不再切割、压缩与清理，
+写入前至多每隔 ExternalCheckInterval 检查一次文件是否被移动或删除，是则在原路径重新创建；
+也可以通过 Reopen 主动重新打开，收到 SIGHUP 时会自动调用。
+*/
+type RotateFile struct {
+	path   string
+	rotate RotateConfig
+
+	mu      sync.Mutex
+	closed  bool // 是否已被 retire 永久关闭。
+	file    *os.File
+	info    os.FileInfo // 当前打开的文件，用于判断路径上的文件是否已被替换。
+	checked time.Time   // 外部切割模式下最近一次检查文件是否被替换的时间。
+	size    int64
+	period  time.Time // 当前文件所属周期的开始时间，不按时间切割时为零值。
+
+	millMu sync.Mutex     // 保证旧文件依次压缩与清理。
+	mills  sync.WaitGroup // 尚未完成的压缩与清理，Close 时等待其结束。
+}
+
+// NewRotateFile 创建按 rotate 切割的日志文件，文件在第一次写入时才会打开。
+// @param path string: 日志文件的路径，所在目录不存在时会自动创建。
+// @param rotate RotateConfig: 切割策略。
+// @return *RotateFile: 日志文件，调用方负责在不再使用时将其关闭。
+func NewRotateFile(path string, rotate RotateConfig) *RotateFile {
+	return &RotateFile{path: path, rotate: rotate}
+}
+
+// Write 写入 p，写入前按需切割。
+func (f *RotateFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, f.errClosed()
+	}
+	if f.file == nil {
+		if err := f.openExisting(); err != nil {
+			return 0, err
+		}
+	}
+	if f.rotate.External {
+		// 每次写入都调用 os.Stat 的开销较大，检查按间隔进行，期间沿用上一次的结果
+		if now := time.Now(); now.Sub(f.checked) >= f.rotate.externalCheckInterval() {
+			f.checked = now
+			if f.replaced() {
+				if err := f.reopenLocked(); err != nil {
+					return 0, err
+				}
+			}
+		}
+		n, err := f.file.Write(p)
+		f.size += int64(n)
+		return n, err
+	}
+	now := time.Now()
+	if !f.period.Equal(f.rotate.periodStart(now)) {
+		if err := f.rotateLocked(now); err != nil {
+			return 0, err
+		}
+	}
+	if max := f.rotate.maxBytes(); max > 0 && f.size > 0 && f.size+int64(len(p)) > max {
+		if err := f.rotateLocked(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Sync 将已写入的内容同步到磁盘。
+func (f *RotateFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close 关闭当前文件，并等待后台的压缩与清理完成。关闭后再次写入会重新打开文件。
+func (f *RotateFile) Close() error {
+	f.mu.Lock()
+	err := f.closeLocked()
+	f.mu.Unlock()
+	f.mills.Wait()
+	return err
+}
+
+// retire 与 Close 相同，但之后的写入、切割与重新打开都会返回错误，
+// 用于被 Reload 替换的文件，避免仍持有旧核心的写入重新打开文件。
+func (f *RotateFile) retire() error {
+	f.mu.Lock()
+	f.closed = true
+	err := f.closeLocked()
+	f.mu.Unlock()
+	f.mills.Wait()
+	return err
+}
+
+// errClosed 返回写入已永久关闭的文件时的错误。
+func (f *RotateFile) errClosed() error {
+	return fmt.Errorf("logger: %s: %w", f.path, os.ErrClosed)
+}
+
+// Rotate 立即切割当前文件，即使它没有达到切割条件。External 为 true 时与 Reopen 相同。
+func (f *RotateFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return f.errClosed()
+	}
+	if f.rotate.External {
+		return f.reopenLocked()
+	}
+	if f.file == nil {
+		if err := f.openExisting(); err != nil {
+			return err
+		}
+	}
+	return f.rotateLocked(time.Now())
+}
+
+// Reopen 关闭当前文件并按原路径重新打开，文件不存在时创建，用于配合外部工具（如 logrotate）切割。
+// 重新打开期间的写入会等待其完成，不会丢失；打开失败时继续写入原来的文件。
+func (f *RotateFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return f.errClosed()
+	}
+	return f.reopenLocked()
+}
+
+// reopenLocked 先打开路径上的文件再关闭原来的文件，调用方需持有 f.mu。
+func (f *RotateFile) reopenLocked() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("logger: create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("logger: reopen log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("logger: reopen log file: %w", err)
+	}
+	_ = f.closeLocked()
+	f.file, f.info, f.size = file, info, info.Size()
+	f.checked = time.Now()
+	return nil
+}
+
+// replaced 判断路径上的文件是否已被移动、删除或替换为其它文件。
+func (f *RotateFile) replaced() bool {
+	info, err := os.Stat(f.path)
+	return err != nil || f.info == nil || !os.SameFile(info, f.info)
+}
+
+// closeLocked 关闭当前文件，调用方需持有 f.mu。
+func (f *RotateFile) closeLocked() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file, f.info = nil, nil
+	return err
+}
+
+// openExisting 打开已存在的日志文件继续写入，文件不存在时创建。
+// 已存在的文件属于之前的周期时先将其切割。
+func (f *RotateFile) openExisting() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrNotExist) {
+		return f.openNew(time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("logger: open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("logger: stat log file: %w", err)
+	}
+	f.file, f.info, f.size = file, info, info.Size()
+	f.period = f.rotate.periodStart(info.ModTime())
+	return nil
+}
+
+// openNew 创建新的日志文件，其所属周期为 now 所在的周期。
+func (f *RotateFile) openNew(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("logger: create log directory: %w", err)
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode()
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, mode)
+	if err != nil {
+		return fmt.Errorf("logger: open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("logger: open log file: %w", err)
+	}
+	f.file, f.info, f.size = file, info, 0
+	f.period = f.rotate.periodStart(now)
+	return nil
+}
+
+// rotateLocked 将当前文件重命名为旧文件并创建新文件，之后在后台压缩与清理旧文件。调用方需持有 f.mu。
+func (f *RotateFile) rotateLocked(now time.Time) error {
+	if err := f.closeLocked(); err != nil {
+		return err
+	}
+	backup := f.backupName(now)
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("logger: rotate log file: %w", err)
+	}
+	if err := f.openNew(now); err != nil {
+		return err
+	}
+	f.mills.Add(1)
+	go f.mill(backup)
+	return nil
+}
+
+// backupName 返回当前文件切割后的旧文件名，与已有的旧文件重名时追加序号。
+func (f *RotateFile) backupName(now time.Time) string {
+	var stamp string
+	switch f.rotate.Interval {
+	case RotateHourly:
+		stamp = f.period.Format(hourlyLayout)
+	case RotateDaily:
+		stamp = f.period.Format(dailyLayout)
+	default:
+		stamp = now.Format(sizeBackupLayout)
+	}
+	prefix, ext := f.prefixAndExt()
+	name := prefix + stamp
+	for i := 1; backupExists(name + ext); i++ {
+		name = prefix + stamp + "." + strconv.Itoa(i)
+	}
+	return name + ext
+}
+
+// prefixAndExt 返回旧文件名的前缀（含目录与 "-"）以及扩展名。
+func (f *RotateFile) prefixAndExt() (string, string) {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-", ext
+}
+
+// backupExists 判断旧文件（包括压缩后的文件）是否已经存在。
+func backupExists(name string) bool {
+	for _, suffix := range []string{"", ".gz", ".zst"} {
+		if _, err := os.Stat(name + suffix); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// mill 压缩旧文件、调用 RotateHook，并清理超出保留策略的旧文件。
+func (f *RotateFile) mill(backup string) {
+	defer f.mills.Done()
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	path, err := compressFile(backup, f.rotate.compression())
+	if err == nil {
+		for _, hook := range f.rotate.OnRotate {
+			hook(path)
+		}
+	}
+	f.cleanup()
+}
+
+// compressFile 按 compression 压缩 path 并删除原文件，返回压缩后的路径。不压缩时原样返回 path。
+func compressFile(path string, compression Compression) (string, error) {
+	var suffix string
+	switch compression {
+	case CompressionGzip:
+		suffix = ".gz"
+	case CompressionZstd:
+		suffix = ".zst"
+	default:
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return "", err
+	}
+	// 先写入临时文件，完成后再重命名，避免留下不完整的压缩文件
+	tmp := path + suffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return "", err
+	}
+	var w io.WriteCloser
+	if compression == CompressionGzip {
+		w = gzip.NewWriter(dst)
+	} else if w, err = zstd.NewWriter(dst); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	_, err = io.Copy(w, src)
+	err = errors.Join(err, w.Close(), dst.Close())
+	if err == nil {
+		err = os.Rename(tmp, path+suffix)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	src.Close()
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return path + suffix, nil
+}
+
+// backupFile 是目录中属于该日志文件的一个旧文件。
+type backupFile struct {
+	path  string
+	stamp time.Time
+	seq   int
+	size  int64
+}
+
+// backups 返回全部旧文件，按从新到旧排列。
+func (f *RotateFile) backups() ([]backupFile, error) {
+	prefix, ext := f.prefixAndExt()
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil, err
+	}
+	base := filepath.Base(prefix)
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, base)
+		for _, suffix := range []string{".gz", ".zst"} {
+			stamp = strings.TrimSuffix(stamp, suffix)
+		}
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		t, seq, ok := parseBackupStamp(strings.TrimSuffix(stamp, ext))
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(filepath.Dir(f.path), name), stamp: t, seq: seq, size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].stamp.Equal(backups[j].stamp) {
+			return backups[i].stamp.After(backups[j].stamp)
+		}
+		return backups[i].seq > backups[j].seq
+	})
+	return backups, nil
+}
+
+// parseBackupStamp 解析旧文件名中的时间与序号。
+func parseBackupStamp(stamp string) (time.Time, int, bool) {
+	for _, layout := range []string{sizeBackupLayout, hourlyLayout, dailyLayout} {
+		if t, err := time.ParseInLocation(layout, stamp, time.Local); err == nil {
+			return t, 0, true
+		}
+	}
+	i := strings.LastIndexByte(stamp, '.')
+	if i < 0 {
+		return time.Time{}, 0, false
+	}
+	seq, err := strconv.Atoi(stamp[i+1:])
+	if err != nil || seq <= 0 {
+		return time.Time{}, 0, false
+	}
+	t, _, ok := parseBackupStamp(stamp[:i])
+	return t, seq, ok
+}
+
+// cleanup 按 MaxBackups、MaxAge 与 MaxTotalSize 删除更早的旧文件。
+func (f *RotateFile) cleanup() {
+	if f.rotate.MaxBackups == 0 && f.rotate.MaxAge == 0 && f.rotate.MaxTotalSize == 0 {
+		return
+	}
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -f.rotate.MaxAge)
+	maxTotal := int64(f.rotate.MaxTotalSize) * megabyte
+	var total int64
+	for i, backup := range backups {
+		total += backup.size
+		if (f.rotate.MaxBackups > 0 && i >= f.rotate.MaxBackups) ||
+			(f.rotate.MaxAge > 0 && backup.stamp.Before(cutoff)) ||
+			(maxTotal > 0 && total > maxTotal) {
+			_ = os.Remove(backup.path)
+		}
+	}
+}
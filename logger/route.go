@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+/*
+RouteConfig 描述一个输出接收哪些日志，与 SinkConfig.Level 一起将不同类别的日志分别写入不同的文件，
+如错误日志写入 error.log、SQL 写入 sql.log、请求日志写入 access.log。
+
+属性说明：
+  - Loggers：日志记录器名称，同时匹配其子日志记录器，如 "http" 匹配 "http" 与 "http.client"。
+  - Tags：日志消息的前缀标签，如 "[GORM]"、"[Receive]"、"[Return]"。
+  - Exclude：为 true 时反过来只接收不匹配的日志，用于将已分流的日志从汇总文件中排除。
+
+日志的记录器名称匹配 Loggers 中任意一项，或消息以 Tags 中任意一项开头时视为匹配。
+*/
+type RouteConfig struct {
+	Loggers []string
+	Tags    []string
+	Exclude bool
+}
+
+// validate 检查路由规则是否至少包含一个日志记录器名称或标签。
+func (cfg RouteConfig) validate() error {
+	if len(cfg.Loggers) == 0 && len(cfg.Tags) == 0 {
+		return fmt.Errorf("logger: route requires loggers or tags")
+	}
+	for _, name := range cfg.Loggers {
+		if name == "" {
+			return fmt.Errorf("logger: route logger names must not be empty")
+		}
+	}
+	for _, tag := range cfg.Tags {
+		if tag == "" {
+			return fmt.Errorf("logger: route tags must not be empty")
+		}
+	}
+	return nil
+}
+
+// matches 判断日志是否匹配路由规则，不考虑 Exclude。
+func (cfg RouteConfig) matches(ent zapcore.Entry) bool {
+	for _, name := range cfg.Loggers {
+		if ent.LoggerName == name || strings.HasPrefix(ent.LoggerName, name+".") {
+			return true
+		}
+	}
+	for _, tag := range cfg.Tags {
+		if strings.HasPrefix(ent.Message, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// sinkAccepts 判断输出的核心是否会写入日志，同时考虑输出的级别与路由规则。
+func sinkAccepts(core zapcore.Core, ent zapcore.Entry) bool {
+	if r, ok := core.(*routeCore); ok {
+		return r.route.matches(ent) != r.route.Exclude && r.Core.Enabled(ent.Level)
+	}
+	return core.Enabled(ent.Level)
+}
+
+// routeCore 在 zapcore.Core 之外按 RouteConfig 过滤日志，只将接收的日志交给内部核心。
+type routeCore struct {
+	zapcore.Core
+	route RouteConfig
+}
+
+// With 返回附加了字段的新核心，并保留路由规则。
+func (c *routeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &routeCore{Core: c.Core.With(fields), route: c.route}
+}
+
+// Check 先按路由规则过滤，再交由内部核心决定是否输出。
+func (c *routeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.route.matches(ent) == c.route.Exclude {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxSuppressedKeys 是采样统计中单独计数的消息种类上限，超出后合并为一条统计。
+const maxSuppressedKeys = 1024
+
+/*
+SamplingConfig 描述日志采样与限流策略，作用于全部输出，未设置时不采样也不限流。
+
+属性说明：
+  - Interval：采样周期，不大于0时使用1秒。
+  - First：每个周期内，同一级别、同一消息的日志先完整输出 First 条，不大于0时不按消息采样。
+  - Thereafter：超出 First 条后每 Thereafter 条输出1条，为0时丢弃超出的全部日志。
+  - Rate：每个日志记录器（按名称区分）每秒最多输出的条数，以令牌桶实现，不大于0时不限流。
+  - Burst：令牌桶容量，即允许的瞬时突发条数，不大于0时与 Rate 相同（至少为1）。
+  - SummaryInterval：输出 "suppressed N similar entries" 统计日志的间隔，不大于0时使用10秒。
+    统计日志使用被丢弃日志的级别与日志记录器名称，sampled 字段为被丢弃日志的消息。
+*/
+type SamplingConfig struct {
+	Interval        time.Duration
+	First           int
+	Thereafter      int
+	Rate            float64
+	Burst           int
+	SummaryInterval time.Duration
+}
+
+// DefaultSamplingConfig 返回默认的采样策略：每秒同一消息先输出100条，之后每100条输出1条，不限流，每10秒输出一次统计。
+func DefaultSamplingConfig() SamplingConfig {
+	return SamplingConfig{
+		Interval:        time.Second,
+		First:           100,
+		Thereafter:      100,
+		SummaryInterval: 10 * time.Second,
+	}
+}
+
+// withDefaults 用默认值补全未设置的周期与令牌桶容量。
+func (cfg SamplingConfig) withDefaults() SamplingConfig {
+	def := DefaultSamplingConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.SummaryInterval <= 0 {
+		cfg.SummaryInterval = def.SummaryInterval
+	}
+	if cfg.Rate > 0 && cfg.Burst <= 0 {
+		cfg.Burst = int(cfg.Rate)
+		if cfg.Burst < 1 {
+			cfg.Burst = 1
+		}
+	}
+	return cfg
+}
+
+// validate 检查采样参数是否合法。
+func (cfg SamplingConfig) validate() error {
+	if cfg.Thereafter < 0 {
+		return fmt.Errorf("logger: sampling thereafter must not be negative, got %d", cfg.Thereafter)
+	}
+	return nil
+}
+
+// suppressedKey 区分统计日志中不同种类的被丢弃日志。
+type suppressedKey struct {
+	level   zapcore.Level
+	logger  string
+	message string
+}
+
+// sampler 记录被采样与限流丢弃的日志，并定期输出统计日志。
+type sampler struct {
+	out zapcore.Core // 统计日志直接写入的核心，不再经过采样与限流。
+	cfg SamplingConfig
+
+	mu         sync.Mutex
+	suppressed map[suppressedKey]uint64
+	buckets    map[string]*tokenBucket // 按日志记录器名称区分的令牌桶。
+	total      atomic.Uint64           // 累计丢弃的条数。
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// newSampler 在 out 之外包装采样与限流，返回包装后的核心，并启动输出统计日志的后台 goroutine。
+// accepts 判断日志是否会被至少一个输出写入，不会写入的日志不参与采样与限流，也不计入丢弃统计。
+func newSampler(out zapcore.Core, cfg SamplingConfig, accepts func(zapcore.Entry) bool) (zapcore.Core, *sampler) {
+	cfg = cfg.withDefaults()
+	s := &sampler{
+		out:        out,
+		cfg:        cfg,
+		suppressed: make(map[suppressedKey]uint64),
+		buckets:    make(map[string]*tokenBucket),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go s.run()
+
+	core := out
+	if cfg.Rate > 0 {
+		core = &rateLimitCore{Core: core, s: s}
+	}
+	if cfg.First > 0 {
+		core = zapcore.NewSamplerWithOptions(core, cfg.Interval, cfg.First, cfg.Thereafter,
+			zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+				if dec&zapcore.LogDropped > 0 {
+					s.suppress(ent)
+				}
+			}))
+	}
+	return &acceptCore{Core: core, accepts: accepts}, s
+}
+
+// acceptCore 在采样与限流之前排除没有输出会写入的日志（如被各输出的级别或路由规则过滤），
+// 避免它们消耗令牌，进而挤掉其它输出需要的日志。
+type acceptCore struct {
+	zapcore.Core
+	accepts func(zapcore.Entry) bool
+}
+
+// With 返回附加了字段的新核心，输出是否接收日志与字段无关。
+func (c *acceptCore) With(fields []zapcore.Field) zapcore.Core {
+	return &acceptCore{Core: c.Core.With(fields), accepts: c.accepts}
+}
+
+// Check 只将会被写入的日志交给采样与限流。
+func (c *acceptCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.accepts(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// suppress 记录一条被丢弃的日志。
+func (s *sampler) suppress(ent zapcore.Entry) {
+	s.total.Add(1)
+	key := suppressedKey{level: ent.Level, logger: ent.LoggerName, message: ent.Message}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.suppressed[key]; !ok && len(s.suppressed) >= maxSuppressedKeys {
+		// 消息种类过多时不再单独计数，避免统计本身占用过多内存
+		key = suppressedKey{level: ent.Level, message: "*"}
+	}
+	s.suppressed[key]++
+}
+
+// allow 判断名为 name 的日志记录器是否还有令牌。
+func (s *sampler) allow(name string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, ok := s.buckets[name]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(s.cfg.Burst), last: now}
+		s.buckets[name] = bucket
+	}
+	return bucket.take(s.cfg.Rate, float64(s.cfg.Burst), now)
+}
+
+// run 按 SummaryInterval 定期输出统计日志。
+func (s *sampler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush 为每种被丢弃的日志输出一条统计日志，并清空计数。
+func (s *sampler) flush() {
+	s.mu.Lock()
+	suppressed := s.suppressed
+	s.suppressed = make(map[suppressedKey]uint64, len(suppressed))
+	s.mu.Unlock()
+
+	now := time.Now()
+	for key, n := range suppressed {
+		ent := zapcore.Entry{
+			Level:      key.level,
+			LoggerName: key.logger,
+			Time:       now,
+			Message:    fmt.Sprintf("[Sampling] suppressed %d similar entries", n),
+		}
+		if ce := s.out.Check(ent, nil); ce != nil {
+			ce.Write(zap.String("sampled", key.message), zap.Uint64("suppressed", n))
+		}
+	}
+}
+
+// Suppressed 返回累计丢弃的日志条数。
+func (s *sampler) Suppressed() uint64 {
+	return s.total.Load()
+}
+
+// Close 停止后台 goroutine，并输出尚未输出的统计日志。
+func (s *sampler) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	s.flush()
+	return nil
+}
+
+// tokenBucket 是按时间匀速补充令牌的令牌桶。
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take 按经过的时间补充令牌，并尝试取走一个令牌。
+func (b *tokenBucket) take(rate, burst float64, now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimitCore 按日志记录器名称限制每秒输出的条数。
+type rateLimitCore struct {
+	zapcore.Core
+	s *sampler
+}
+
+// With 返回附加了字段的新核心，与原核心共享令牌桶。
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), s: c.s}
+}
+
+// Check 在级别允许且令牌充足时交由内部核心处理，令牌不足时丢弃并计数。
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if !c.s.allow(ent.LoggerName, ent.Time) {
+		c.s.suppress(ent)
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,303 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogGroup 是 slog.Handler.WithGroup 打开的分组及其中通过 WithAttrs 附加的属性。
+type slogGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// slogHandler 是写入 GLogger 日志核心的 slog.Handler。
+type slogHandler struct {
+	log    *GLogger
+	name   string
+	groups []slogGroup // 第一个元素为根分组，名称为空。
+}
+
+// NewSlogHandler 返回写入 log 的 slog.Handler，日志经过 log 的级别、输出与脱敏规则，
+// 并自动附加 context.Context 中的追踪ID与字段。slog 的分组对应嵌套的对象字段。
+//
+// 示例：
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(monophonic.Default())))
+//	slog.InfoContext(ctx, "user logged in", "user", "alice")
+func NewSlogHandler(log *GLogger) slog.Handler {
+	return &slogHandler{
+		log:    log,
+		name:   log.name,
+		groups: []slogGroup{{}},
+	}
+}
+
+// core 返回当前的日志核心，每次调用时重新获取，使 Reload 与 SetLogLevel 对已创建的 Handler 同样生效。
+func (h *slogHandler) core() zapcore.Core {
+	return h.log.base().Core()
+}
+
+// Enabled 判断 level 级别的日志是否可能输出，按日志记录器名称设置的级别在 Handle 中判断。
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core().Enabled(zapLevelFromSlog(level))
+}
+
+// Handle 将 slog.Record 转换为日志条目写入日志核心。
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	ent := zapcore.Entry{
+		Level:      zapLevelFromSlog(record.Level),
+		Time:       record.Time,
+		LoggerName: h.name,
+		Message:    record.Message,
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ent.Caller = zapcore.EntryCaller{Defined: true, PC: frame.PC, File: frame.File, Line: frame.Line, Function: frame.Function}
+	}
+	ce := h.core().Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	// 自内向外合并分组，没有任何属性的分组按 slog 的约定省略
+	for i := len(h.groups) - 1; i > 0; i-- {
+		attrs = append(h.groups[i].attrs[:len(h.groups[i].attrs):len(h.groups[i].attrs)], attrs...)
+		if len(attrs) > 0 {
+			attrs = []slog.Attr{{Key: h.groups[i].name, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	attrs = append(h.groups[0].attrs[:len(h.groups[0].attrs):len(h.groups[0].attrs)], attrs...)
+
+	ce.Write(withContextFields(ctx, slogAttrsToFields(attrs))...)
+	return nil
+}
+
+// WithAttrs 返回在当前分组中附加了 attrs 的新 Handler。
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	groups := make([]slogGroup, len(h.groups))
+	copy(groups, h.groups)
+	last := &groups[len(groups)-1]
+	last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], attrs...)
+	return &slogHandler{log: h.log, name: h.name, groups: groups}
+}
+
+// WithGroup 返回打开了名为 name 的分组的新 Handler，之后的属性都位于该分组中。
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]slogGroup, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &slogHandler{log: h.log, name: h.name, groups: append(groups, slogGroup{name: name})}
+}
+
+// slogAttrsToFields 将 slog 属性转换为 zap 字段，键为空的分组展开到上一级。
+func slogAttrsToFields(attrs []slog.Attr) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+		if attr.Value.Kind() == slog.KindGroup {
+			group := attr.Value.Group()
+			if len(group) == 0 {
+				continue
+			}
+			if attr.Key == "" {
+				fields = append(fields, slogAttrsToFields(group)...)
+				continue
+			}
+			fields = append(fields, zap.Object(attr.Key, slogGroupMarshaler(group)))
+			continue
+		}
+		fields = append(fields, slogAttrToField(attr))
+	}
+	return fields
+}
+
+// slogAttrToField 转换单个非分组属性。
+func slogAttrToField(attr slog.Attr) zapcore.Field {
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return zap.String(attr.Key, attr.Value.String())
+	case slog.KindInt64:
+		return zap.Int64(attr.Key, attr.Value.Int64())
+	case slog.KindUint64:
+		return zap.Uint64(attr.Key, attr.Value.Uint64())
+	case slog.KindFloat64:
+		return zap.Float64(attr.Key, attr.Value.Float64())
+	case slog.KindBool:
+		return zap.Bool(attr.Key, attr.Value.Bool())
+	case slog.KindDuration:
+		return zap.Duration(attr.Key, attr.Value.Duration())
+	case slog.KindTime:
+		return zap.Time(attr.Key, attr.Value.Time())
+	default:
+		if err, ok := attr.Value.Any().(error); ok {
+			return zap.NamedError(attr.Key, err)
+		}
+		return zap.Any(attr.Key, attr.Value.Any())
+	}
+}
+
+// slogGroupMarshaler 将 slog 分组编码为嵌套的对象字段。
+type slogGroupMarshaler []slog.Attr
+
+// MarshalLogObject 依次编码分组中的属性。
+func (g slogGroupMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, field := range slogAttrsToFields(g) {
+		field.AddTo(enc)
+	}
+	return nil
+}
+
+// zapLevelFromSlog 将 slog 级别转换为 zap 级别，介于两个标准级别之间的按较低者处理。
+func zapLevelFromSlog(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// slogLevelFromZap 将 zap 级别转换为 slog 级别，DPanic 及以上级别在 LevelError 之上依次递增。
+func slogLevelFromZap(level zapcore.Level) slog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError + slog.Level(level-zapcore.ErrorLevel)
+	}
+}
+
+// slogCore 是写入 slog.Handler 的 zapcore.Core。
+type slogCore struct {
+	handler slog.Handler
+	fields  []zapcore.Field // 通过 With 附加的字段，写入时与日志自身的字段一并转换，保证命名空间的嵌套正确。
+}
+
+// NewGLoggerFromSlog 创建写入 handler 的 GLogger，输出、编码与级别均由 handler 决定，
+// 仍可通过 SetLogLevel 在其之上进一步限制。日志记录器名称以 "logger" 属性输出，
+// zap 的字段按原有顺序转换为 slog 属性，命名空间对应 slog 分组。
+// 此实例不支持 Reload，Close 只会刷新日志。
+//
+// 示例：
+//
+//	glogger := logger.NewGLoggerFromSlog(slog.NewJSONHandler(os.Stdout, nil))
+func NewGLoggerFromSlog(handler slog.Handler) *GLogger {
+	return NewGLoggerFromCore(&slogCore{handler: handler})
+}
+
+// Enabled 交由 handler 判断。
+func (c *slogCore) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), slogLevelFromZap(level))
+}
+
+// With 返回附加了字段的新核心。
+func (c *slogCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &slogCore{handler: c.handler, fields: merged}
+}
+
+// Check 在级别允许时将自身加入待写入的核心列表。
+func (c *slogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 将日志条目转换为 slog.Record 交给 handler 处理。
+func (c *slogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	record := slog.NewRecord(ent.Time, slogLevelFromZap(ent.Level), ent.Message, ent.Caller.PC)
+	if ent.LoggerName != "" {
+		record.AddAttrs(slog.String("logger", ent.LoggerName))
+	}
+	if len(c.fields) > 0 {
+		fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	}
+	record.AddAttrs(zapFieldsToSlog(fields)...)
+	if ent.Stack != "" {
+		record.AddAttrs(slog.String("stacktrace", ent.Stack))
+	}
+	return c.handler.Handle(context.Background(), record)
+}
+
+// Sync 没有需要刷新的缓冲。
+func (c *slogCore) Sync() error {
+	return nil
+}
+
+// zapFieldsToSlog 将 zap 字段按顺序转换为 slog 属性，命名空间之后的字段放入对应的分组。
+func zapFieldsToSlog(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case zapcore.SkipType:
+			continue
+		case zapcore.NamespaceType:
+			if rest := zapFieldsToSlog(fields[i+1:]); len(rest) > 0 {
+				attrs = append(attrs, slog.Group(field.Key, anyAttrs(rest)...))
+			}
+			return attrs
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		attrs = append(attrs, slogAttrsFromMap(enc.Fields)...)
+	}
+	return attrs
+}
+
+// slogAttrsFromMap 将编码后的字段转换为 slog 属性，嵌套对象转换为分组，键按字典序排列。
+func slogAttrsFromMap(m map[string]any) []slog.Attr {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		if nested, ok := m[k].(map[string]any); ok {
+			attrs = append(attrs, slog.Group(k, anyAttrs(slogAttrsFromMap(nested))...))
+			continue
+		}
+		attrs = append(attrs, slog.Any(k, m[k]))
+	}
+	return attrs
+}
+
+// anyAttrs 将 []slog.Attr 转换为 slog.Group 接受的参数。
+func anyAttrs(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return args
+}
@@ -0,0 +1,309 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TagSpan 定义了日志记录中的 span 标签，用于标记 span 结束时的记录。
+const TagSpan = "[Span]"
+
+// maxSpansPerTrace 是单个追踪最多记录的 span 数量，超出后只输出日志、不再记录，避免循环中的查询占用过多内存。
+const maxSpansPerTrace = 1024
+
+/*
+Span 是一次内置 span 计时的记录，同一追踪中的 span 共享请求的追踪ID，并通过 ParentID 组成嵌套关系。
+
+属性说明：
+  - TraceID：所属请求的追踪ID。
+  - SpanID：span 的ID，为 16 位小写十六进制。
+  - ParentID：上一层 span 的ID，最外层的 span 为空。
+  - Name：span 的名称，如 "GET /users"、"gorm.query"。
+  - Start：开始时间。
+  - End：结束时间，尚未结束时为零值。
+  - Fields：结束时附加的日志字段。
+*/
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Name     string
+	Start    time.Time
+	End      time.Time
+	Fields   []zapcore.Field
+}
+
+// Duration 返回 span 的耗时，尚未结束时返回到目前为止的耗时。
+func (s Span) Duration() time.Duration {
+	if s.End.IsZero() {
+		return time.Since(s.Start)
+	}
+	return s.End.Sub(s.Start)
+}
+
+// spanRecorder 记录同一追踪中的全部 span，由请求的 context.Context 及其派生的上下文共享。
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+// add 记录 span 并返回其下标，超出 maxSpansPerTrace 时返回 -1。
+func (r *spanRecorder) add(span Span) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.spans) >= maxSpansPerTrace {
+		return -1
+	}
+	r.spans = append(r.spans, span)
+	return len(r.spans) - 1
+}
+
+// end 结束下标为 i 的 span，并返回其副本。
+func (r *spanRecorder) end(i int, span Span) Span {
+	if i < 0 {
+		return span
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans[i] = span
+	return span
+}
+
+// snapshot 返回全部 span 的副本。
+func (r *spanRecorder) snapshot() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := make([]Span, len(r.spans))
+	copy(spans, r.spans)
+	return spans
+}
+
+// newSpan 在 ctx 的追踪中创建名为 name 的 span，返回携带该 span 的 context.Context。
+// ctx 中没有追踪ID时生成一个，没有记录器时创建一个，该 span 即为追踪中最外层的 span。
+func newSpan(ctx context.Context, name string, start time.Time, generate func() string) (context.Context, *spanRecorder, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	data := dataFromContext(ctx)
+	if data.traceID == "" {
+		data.traceID = generate()
+	}
+	if data.spans == nil {
+		data.spans = &spanRecorder{}
+	}
+	span := Span{TraceID: data.traceID, SpanID: NewSpanID(), ParentID: data.spanID, Name: name, Start: start}
+	data.spanID = span.SpanID
+	return context.WithValue(ctx, contextKey{}, data), data.spans, span
+}
+
+// StartSpan 在 ctx 的追踪中开始一个名为 name 的 span，返回携带该 span 的 context.Context 与结束函数，
+// 之后以返回的上下文开始的 span 都嵌套在其中，ctx 中没有追踪ID时生成一个 UUIDv4。与 GLogger.Start 不同，结束时不输出日志，
+// 适合自行记录日志的场景，如 GinLogger 以请求日志代替 span 日志。
+// @param ctx context.Context: 父级上下文，通常为请求的 context.Context。
+// @param name string: span 的名称。
+// @return context.Context: 携带该 span 的新上下文。
+// @return func(fields ...zapcore.Field) Span: 结束函数，fields 会记录在 span 上，返回结束后的 span。
+func StartSpan(ctx context.Context, name string) (context.Context, func(fields ...zapcore.Field) Span) {
+	return startSpan(ctx, name, UUIDv4Generator().Generate)
+}
+
+// startSpan 开始 span，并在 ctx 中没有追踪ID时使用 generate 生成。
+func startSpan(ctx context.Context, name string, generate func() string) (context.Context, func(fields ...zapcore.Field) Span) {
+	ctx, recorder, span := newSpan(ctx, name, time.Now(), generate)
+	i := recorder.add(span)
+	var once sync.Once
+	return ctx, func(fields ...zapcore.Field) Span {
+		once.Do(func() {
+			span.End = time.Now()
+			span.Fields = fields
+			span = recorder.end(i, span)
+		})
+		return span
+	}
+}
+
+// RecordSpan 在 ctx 的追踪中记录一个已经结束的 span，不输出日志，
+// 用于事后才得知起止时间的操作，如 GormLogger.Trace 中的 SQL 查询。
+// ctx 中没有通过 StartSpan 或 GLogger.Start 开始的 span 时不记录，并返回零值。
+// @param ctx context.Context: 所属请求的上下文。
+// @param name string: span 的名称。
+// @param start time.Time: 开始时间。
+// @param end time.Time: 结束时间。
+// @param fields ...zapcore.Field: 记录在 span 上的日志字段。
+// @return Span: 记录的 span。
+func RecordSpan(ctx context.Context, name string, start, end time.Time, fields ...zapcore.Field) Span {
+	if dataFromContext(ctx).spans == nil {
+		return Span{}
+	}
+	_, recorder, span := newSpan(ctx, name, start, UUIDv4Generator().Generate)
+	span.End = end
+	span.Fields = fields
+	recorder.add(span)
+	return span
+}
+
+// SpanIDFromContext 返回 ctx 中当前所在的内置 span（见 StartSpan 与 GLogger.Start）的ID，不存在时返回空字符串。
+func SpanIDFromContext(ctx context.Context) string {
+	return dataFromContext(ctx).spanID
+}
+
+// SpansFromContext 返回 ctx 所属追踪中已经记录的全部 span（包括尚未结束的），按开始的先后排列。
+// ctx 中没有 span 时返回 nil。
+func SpansFromContext(ctx context.Context) []Span {
+	recorder := dataFromContext(ctx).spans
+	if recorder == nil {
+		return nil
+	}
+	spans := recorder.snapshot()
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start.Before(spans[j].Start) })
+	return spans
+}
+
+// Start 在 ctx 的追踪中开始一个名为 name 的 span，返回携带该 span 的 context.Context 与结束函数。
+// 结束时以调试级别输出一条包含 spanId、parentSpanId 与耗时的日志，caller 指向调用结束函数的位置。
+// ctx 中没有追踪ID时通过 GenerateTraceId 生成。
+//
+// 示例：
+//
+//	ctx, end := monophonic.Default().Start(c.Request.Context(), "load orders")
+//	defer end()
+//
+// @param ctx context.Context: 父级上下文，通常为请求的 context.Context。
+// @param name string: span 的名称。
+// @return context.Context: 携带该 span 的新上下文，之后的 span 都嵌套在其中。
+// @return func(fields ...zapcore.Field): 结束函数，可以多次调用，只有第一次生效。
+func (log *GLogger) Start(ctx context.Context, name string) (context.Context, func(fields ...zapcore.Field)) {
+	ctx, end := startSpan(ctx, name, log.GenerateTraceId)
+	var ended atomic.Bool
+	return ctx, func(fields ...zapcore.Field) {
+		if !ended.CompareAndSwap(false, true) {
+			return
+		}
+		span := end(fields...)
+		logFields := make([]zapcore.Field, 0, len(fields)+3)
+		logFields = append(logFields, zap.String("spanId", span.SpanID))
+		if span.ParentID != "" {
+			logFields = append(logFields, zap.String("parentSpanId", span.ParentID))
+		}
+		logFields = append(logFields, zap.Int64("cost", span.Duration().Milliseconds()))
+		logFields = append(logFields, fields...)
+		// 结束函数相当于 GLogger 的公开方法，caller 指向调用它的位置
+		log.zapLogger(0).Debug(TagSpan+span.Name, withContextFields(ctx, logFields)...)
+	}
+}
+
+// chromeTraceEvent 是 Chrome Trace Event 格式中的一个完整事件（ph 为 "X"）。
+type chromeTraceEvent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat"`
+	Ph   string         `json:"ph"`
+	Ts   float64        `json:"ts"`
+	Dur  float64        `json:"dur"`
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	Args map[string]any `json:"args"`
+}
+
+// chromeTrace 是 Chrome Trace Event 格式的 JSON 对象。
+type chromeTrace struct {
+	TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+// WriteChromeTrace 将 spans 以 Chrome Trace Event 格式的 JSON 写入 w，可以在 chrome://tracing 或 Perfetto 中打开。
+// 时间以第一个 span 的开始时间为0，单位为微秒；并发执行、互不嵌套的 span 会分配到不同的行（tid），
+// 尚未结束的 span 按到目前为止的耗时输出，并在 args 中标记 unfinished。
+// span 的字段原样导出，其中可能包含 SQL 等敏感内容，需要脱敏时请使用 GLogger.WriteChromeTrace。
+// @param w io.Writer: 写入目标。
+// @param spans []Span: 需要导出的 span，通常来自 SpansFromContext。
+// @return error: 写入失败时返回错误。
+func WriteChromeTrace(w io.Writer, spans []Span) error {
+	sorted := make([]Span, len(spans))
+	copy(sorted, spans)
+	// 开始时间相同时较长的 span 在前，使其作为外层
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Start.Equal(sorted[j].Start) {
+			return sorted[i].Start.Before(sorted[j].Start)
+		}
+		return sorted[i].Duration() > sorted[j].Duration()
+	})
+
+	trace := chromeTrace{TraceEvents: make([]chromeTraceEvent, 0, len(sorted)), DisplayTimeUnit: "ms"}
+	var lanes [][]time.Time // 每一行中尚未结束的外层 span 的结束时间，按嵌套顺序排列
+	for _, span := range sorted {
+		end := span.Start.Add(span.Duration())
+		tid := -1
+		for i, open := range lanes {
+			for len(open) > 0 && !open[len(open)-1].After(span.Start) {
+				open = open[:len(open)-1]
+			}
+			lanes[i] = open
+			if tid < 0 && (len(open) == 0 || !open[len(open)-1].Before(end)) {
+				tid = i
+			}
+		}
+		if tid < 0 {
+			tid = len(lanes)
+			lanes = append(lanes, nil)
+		}
+		lanes[tid] = append(lanes[tid], end)
+
+		args := map[string]any{"traceId": span.TraceID, "spanId": span.SpanID}
+		if span.ParentID != "" {
+			args["parentSpanId"] = span.ParentID
+		}
+		if span.End.IsZero() {
+			args["unfinished"] = true
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		for _, f := range span.Fields {
+			f.AddTo(enc)
+		}
+		for k, v := range enc.Fields {
+			args[k] = v
+		}
+		trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{
+			Name: span.Name,
+			Cat:  "span",
+			Ph:   "X",
+			Ts:   float64(span.Start.Sub(sorted[0].Start).Nanoseconds()) / 1e3,
+			Dur:  float64(span.Duration().Nanoseconds()) / 1e3,
+			Pid:  1,
+			Tid:  tid + 1,
+			Args: args,
+		})
+	}
+	return json.NewEncoder(w).Encode(trace)
+}
+
+// WriteChromeTrace 与包级函数 WriteChromeTrace 相同，但导出前按日志记录器的脱敏规则（见 RedactConfig）处理 span 的名称与字段，
+// 与日志一样隐藏 SQL 中的密码、令牌等内容；未配置脱敏规则时原样导出。
+// @param w io.Writer: 写入目标。
+// @param spans []Span: 需要导出的 span，通常来自 SpansFromContext。
+// @return error: 写入失败时返回错误。
+func (log *GLogger) WriteChromeTrace(w io.Writer, spans []Span) error {
+	var r *redactor
+	if log.core != nil {
+		r = log.core.sinks().redact
+	}
+	if r == nil {
+		return WriteChromeTrace(w, spans)
+	}
+	redacted := make([]Span, len(spans))
+	for i, span := range spans {
+		if name, ok := r.redactText(span.Name); ok {
+			span.Name = name
+		}
+		span.Fields = r.redactFields(span.Fields)
+		redacted[i] = span
+	}
+	return WriteChromeTrace(w, redacted)
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+)
+
+// sugarCache 记录由某个 zap.Logger 创建的 zap.SugaredLogger。
+type sugarCache struct {
+	base  *zap.Logger
+	sugar *zap.SugaredLogger
+}
+
+// sugar 返回与 ZapLogger 对应的 zap.SugaredLogger，ZapLogger 未变化时复用缓存。
+// zap.SugaredLogger 自身已经计入了内部的栈帧，因此与 ZapLogger 的 caller 一致。
+// 只能由 GLogger 的公开方法直接调用，以便正确跳过辅助函数。
+func (log *GLogger) sugar() *zap.SugaredLogger {
+	base := log.zapLogger(1)
+	if base != log.base() {
+		// 跳过了辅助函数的 zap.Logger 只用于本次调用，无需缓存
+		return base.Sugar()
+	}
+	if c := log.sugared.Load(); c != nil && c.base == base {
+		return c.sugar
+	}
+	sugar := base.Sugar()
+	log.sugared.Store(&sugarCache{base: base, sugar: sugar})
+	return sugar
+}
+
+// Debugf 按 fmt.Sprintf 的格式记录调试级别的日志，级别未启用时不会格式化参数。
+// @param template string: 格式化模板。
+// @param args ...interface{}: 模板参数。
+func (log *GLogger) Debugf(template string, args ...interface{}) {
+	log.sugar().Debugf(template, args...)
+}
+
+// Infof 按 fmt.Sprintf 的格式记录信息级别的日志，级别未启用时不会格式化参数。
+// @param template string: 格式化模板。
+// @param args ...interface{}: 模板参数。
+func (log *GLogger) Infof(template string, args ...interface{}) {
+	log.sugar().Infof(template, args...)
+}
+
+// Warnf 按 fmt.Sprintf 的格式记录警告级别的日志，级别未启用时不会格式化参数。
+// @param template string: 格式化模板。
+// @param args ...interface{}: 模板参数。
+func (log *GLogger) Warnf(template string, args ...interface{}) {
+	log.sugar().Warnf(template, args...)
+}
+
+// Errorf 按 fmt.Sprintf 的格式记录错误级别的日志，级别未启用时不会格式化参数。
+// @param template string: 格式化模板。
+// @param args ...interface{}: 模板参数。
+func (log *GLogger) Errorf(template string, args ...interface{}) {
+	log.sugar().Errorf(template, args...)
+}
+
+// Fatalf 按 fmt.Sprintf 的格式记录致命错误级别的日志，并在记录后调用 os.Exit(1) 终止程序。
+// @param template string: 格式化模板。
+// @param args ...interface{}: 模板参数。
+func (log *GLogger) Fatalf(template string, args ...interface{}) {
+	log.sugar().Fatalf(template, args...)
+}
+
+// Debugw 记录调试级别的日志，keysAndValues 为交替出现的键与值，也可以直接传入 zap.Field。
+// @param msg string: 日志消息。
+// @param keysAndValues ...interface{}: 交替出现的键与值，如 "user", "alice", "age", 18。
+func (log *GLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	log.sugar().Debugw(msg, keysAndValues...)
+}
+
+// Infow 记录信息级别的日志，keysAndValues 为交替出现的键与值，也可以直接传入 zap.Field。
+// @param msg string: 日志消息。
+// @param keysAndValues ...interface{}: 交替出现的键与值，如 "user", "alice", "age", 18。
+func (log *GLogger) Infow(msg string, keysAndValues ...interface{}) {
+	log.sugar().Infow(msg, keysAndValues...)
+}
+
+// Warnw 记录警告级别的日志，keysAndValues 为交替出现的键与值，也可以直接传入 zap.Field。
+// @param msg string: 日志消息。
+// @param keysAndValues ...interface{}: 交替出现的键与值，如 "user", "alice", "age", 18。
+func (log *GLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	log.sugar().Warnw(msg, keysAndValues...)
+}
+
+// Errorw 记录错误级别的日志，keysAndValues 为交替出现的键与值，也可以直接传入 zap.Field。
+// @param msg string: 日志消息。
+// @param keysAndValues ...interface{}: 交替出现的键与值，如 "user", "alice", "age", 18。
+func (log *GLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	log.sugar().Errorw(msg, keysAndValues...)
+}
+
+// Fatalw 记录致命错误级别的日志，并在记录后调用 os.Exit(1) 终止程序。
+// @param msg string: 日志消息。
+// @param keysAndValues ...interface{}: 交替出现的键与值，如 "user", "alice", "age", 18。
+func (log *GLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	log.sugar().Fatalw(msg, keysAndValues...)
+}
@@ -1,17 +1,367 @@
 package logger
 
-import "github.com/google/uuid"
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TraceIDFormat 是追踪ID的格式。
+type TraceIDFormat string
+
+const (
+	TraceIDUUIDv4    TraceIDFormat = "uuidv4"    // 随机 UUID，如 "1b4e28ba-2fa1-41d2-883f-0016d3cca427"，默认格式。
+	TraceIDUUIDv7    TraceIDFormat = "uuidv7"    // 以毫秒时间戳开头、可按时间排序的 UUID。
+	TraceIDUUID      TraceIDFormat = "uuid"      // 其它版本的 UUID，只由 ParseTraceID 返回。
+	TraceIDULID      TraceIDFormat = "ulid"      // 26 位 Crockford Base32 编码的 ULID，可按时间排序。
+	TraceIDSnowflake TraceIDFormat = "snowflake" // 十进制表示的 Snowflake ID，可按时间排序。
+	TraceIDW3C       TraceIDFormat = "w3c"       // W3C Trace Context 的 trace-id，32 位小写十六进制。
+)
+
+// TraceIDGenerator 生成日志追踪ID，实现需要能在多个 goroutine 中并发调用。
+type TraceIDGenerator interface {
+	Generate() string
+}
+
+// TraceIDGeneratorFunc 将普通函数适配为 TraceIDGenerator。
+type TraceIDGeneratorFunc func() string
+
+// Generate 调用 f 生成追踪ID。
+func (f TraceIDGeneratorFunc) Generate() string {
+	return f()
+}
+
+// UUIDv4Generator 返回生成随机 UUID 的 TraceIDGenerator，即 GenerateTraceId 默认的行为。
+func UUIDv4Generator() TraceIDGenerator {
+	return TraceIDGeneratorFunc(func() string {
+		return uuid.New().String()
+	})
+}
+
+// UUIDv7Generator 返回生成 UUIDv7 的 TraceIDGenerator，同一进程内生成的ID严格递增。
+func UUIDv7Generator() TraceIDGenerator {
+	return TraceIDGeneratorFunc(func() string {
+		return uuid.Must(uuid.NewV7()).String()
+	})
+}
+
+// W3CTraceIDGenerator 返回生成 W3C Trace Context trace-id 的 TraceIDGenerator，
+// 生成的ID可以直接用于 traceparent 请求头，与 OpenTelemetry 等追踪系统互通。
+func W3CTraceIDGenerator() TraceIDGenerator {
+	return TraceIDGeneratorFunc(func() string {
+		var id [16]byte
+		for {
+			mustRandom(id[:])
+			// 全0的 trace-id 是无效值
+			if id != [16]byte{} {
+				return hex.EncodeToString(id[:])
+			}
+		}
+	})
+}
+
+// mustRandom 以密码学安全的随机数填充 b，系统随机源不可用时 panic，与 uuid.New 的行为一致。
+func mustRandom(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("logger: read random: %v", err))
+	}
+}
+
+// crockford 是 ULID 使用的 Crockford Base32 字母表。
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator 生成单调递增的 ULID：同一毫秒内在上一个ID的随机部分上加一。
+type ulidGenerator struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+// ULIDGenerator 返回生成 ULID 的 TraceIDGenerator，同一生成器生成的ID严格递增。
+func ULIDGenerator() TraceIDGenerator {
+	return &ulidGenerator{}
+}
+
+// Generate 生成一个 ULID。
+func (g *ulidGenerator) Generate() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	if ms <= g.lastMs {
+		// 同一毫秒或时钟回拨时沿用上一个时间戳，随机部分加一以保持递增
+		ms = g.lastMs
+		if !increment(g.entropy[:]) {
+			ms++
+			mustRandom(g.entropy[:])
+		}
+	} else {
+		mustRandom(g.entropy[:])
+	}
+	g.lastMs = ms
+
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], g.entropy[:])
+	return encodeULID(id)
+}
+
+// increment 将 b 视为大端序整数加一，溢出时返回 false。
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID 将 128 位的 ULID 编码为 26 位 Crockford Base32 字符串。
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+	var out [26]byte
+	// 最低位字符对应最低的 5 位，共 26*5=130 位，最高 2 位恒为0
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// decodeULID 解码 Crockford Base32 编码的 ULID，不区分大小写。
+func decodeULID(s string) ([16]byte, bool) {
+	var id [16]byte
+	if len(s) != 26 {
+		return id, false
+	}
+	var hi, lo uint64
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(crockford, upper(s[i]))
+		if v < 0 || (i == 0 && v > 7) {
+			// 首个字符只能表示最高的 3 位，超过 7 时会溢出 128 位
+			return id, false
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(id[0:8], hi)
+	binary.BigEndian.PutUint64(id[8:16], lo)
+	return id, true
+}
+
+// upper 将 ASCII 小写字母转换为大写。
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// Snowflake ID 由 41 位毫秒时间戳、10 位节点ID与 12 位序列号组成，时间戳从 Twitter 的纪元开始计算。
+const (
+	snowflakeEpoch    = 1288834974657 // 2010-11-04T01:42:54.657Z，单位毫秒。
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	// MaxSnowflakeNode 是 Snowflake 节点ID的最大值。
+	MaxSnowflakeNode = 1<<snowflakeNodeBits - 1
+)
+
+// snowflakeGenerator 生成 Snowflake ID，同一毫秒内的序列号用尽时借用下一毫秒，不会阻塞。
+type snowflakeGenerator struct {
+	mu     sync.Mutex
+	node   int64
+	lastMs int64
+	seq    int64
+}
+
+// NewSnowflakeGenerator 返回生成 Snowflake ID 的 TraceIDGenerator。
+// 同一集群中的每个进程应使用不同的 node，取值范围为 0 到 MaxSnowflakeNode。
+func NewSnowflakeGenerator(node int64) (TraceIDGenerator, error) {
+	if node < 0 || node > MaxSnowflakeNode {
+		return nil, fmt.Errorf("logger: snowflake node must be between 0 and %d, got %d", MaxSnowflakeNode, node)
+	}
+	return &snowflakeGenerator{node: node}, nil
+}
+
+// Generate 生成一个 Snowflake ID。
+func (g *snowflakeGenerator) Generate() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := time.Now().UnixMilli() - snowflakeEpoch
+	if ms <= g.lastMs {
+		// 同一毫秒或时钟回拨时沿用上一个时间戳，序列号用尽后借用下一毫秒
+		ms = g.lastMs
+		g.seq = (g.seq + 1) & (1<<snowflakeSeqBits - 1)
+		if g.seq == 0 {
+			ms++
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMs = ms
+	return strconv.FormatInt(ms<<(snowflakeNodeBits+snowflakeSeqBits)|g.node<<snowflakeSeqBits|g.seq, 10)
+}
+
+// NewTraceIDGenerator 返回 format 对应的内置 TraceIDGenerator，node 只用于 TraceIDSnowflake。
+// format 为空时返回 UUIDv4Generator。
+func NewTraceIDGenerator(format TraceIDFormat, node int64) (TraceIDGenerator, error) {
+	switch format {
+	case "", TraceIDUUIDv4:
+		return UUIDv4Generator(), nil
+	case TraceIDUUIDv7:
+		return UUIDv7Generator(), nil
+	case TraceIDULID:
+		return ULIDGenerator(), nil
+	case TraceIDSnowflake:
+		return NewSnowflakeGenerator(node)
+	case TraceIDW3C:
+		return W3CTraceIDGenerator(), nil
+	default:
+		return nil, fmt.Errorf("logger: unknown trace id format %q", format)
+	}
+}
+
+// TraceIDInfo 是 ParseTraceID 的解析结果。
+type TraceIDInfo struct {
+	Format TraceIDFormat // 追踪ID的格式。
+	Time   time.Time     // 追踪ID中包含的生成时间，只有 UUIDv7、ULID 与 Snowflake 包含时间，其余为零值。
+}
+
+// ParseTraceID 识别追踪ID的格式，并解析其中包含的生成时间，用于检查请求头等外部传入的追踪ID。
+// 支持标准格式的 UUID（36 位，含连字符）、ULID、Snowflake 与 W3C trace-id，均无法识别时返回错误。
+// Snowflake 要求时间戳晚于纪元，即不小于 4194304（1<<22）的十进制整数。
+func ParseTraceID(id string) (TraceIDInfo, error) {
+	switch {
+	case len(id) == 36:
+		u, err := uuid.Parse(id)
+		if err != nil || u.Variant() != uuid.RFC4122 {
+			break
+		}
+		switch u.Version() {
+		case 4:
+			return TraceIDInfo{Format: TraceIDUUIDv4}, nil
+		case 7:
+			sec, nsec := u.Time().UnixTime()
+			return TraceIDInfo{Format: TraceIDUUIDv7, Time: time.Unix(sec, nsec)}, nil
+		default:
+			return TraceIDInfo{Format: TraceIDUUID}, nil
+		}
+	case len(id) == 32:
+		if isLowerHex(id) && strings.Trim(id, "0") != "" {
+			return TraceIDInfo{Format: TraceIDW3C}, nil
+		}
+	case len(id) == 26:
+		if u, ok := decodeULID(id); ok {
+			ms := int64(binary.BigEndian.Uint16(u[0:2]))<<32 | int64(binary.BigEndian.Uint32(u[2:6]))
+			return TraceIDInfo{Format: TraceIDULID, Time: time.UnixMilli(ms)}, nil
+		}
+	case len(id) > 0 && len(id) <= 19:
+		// 时间戳部分为0的较短数字（如 "42"）更可能是普通的自增ID，不视为 Snowflake；
+		// ParseInt 接受的 "+"、"-" 前缀同样不是合法的 Snowflake ID
+		if !isDigits(id) || id[0] == '0' {
+			break
+		}
+		n, err := strconv.ParseInt(id, 10, 64)
+		ms := n >> (snowflakeNodeBits + snowflakeSeqBits)
+		if err != nil || ms <= 0 {
+			break
+		}
+		return TraceIDInfo{Format: TraceIDSnowflake, Time: time.UnixMilli(ms + snowflakeEpoch)}, nil
+	}
+	return TraceIDInfo{}, fmt.Errorf("logger: invalid trace id %q", id)
+}
+
+// ValidTraceID 判断 id 是否为 ParseTraceID 能够识别的追踪ID。
+func ValidTraceID(id string) bool {
+	_, err := ParseTraceID(id)
+	return err == nil
+}
+
+// isLowerHex 判断 s 是否只包含小写十六进制字符。
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits 判断 s 是否只包含十进制数字。
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// traceIDSource 保存 GLogger 及其子日志记录器共享的 TraceIDGenerator。
+type traceIDSource struct {
+	generator atomic.Pointer[traceIDGeneratorRef]
+}
+
+// traceIDGeneratorRef 包装 TraceIDGenerator，使不同的实现可以存入同一个 atomic.Pointer。
+type traceIDGeneratorRef struct {
+	TraceIDGenerator
+}
+
+// newTraceIDSource 创建使用 generator 的 traceIDSource，generator 为 nil 时使用 UUIDv4Generator。
+func newTraceIDSource(generator TraceIDGenerator) *traceIDSource {
+	s := &traceIDSource{}
+	s.set(generator)
+	return s
+}
+
+// set 替换 TraceIDGenerator，generator 为 nil 时恢复为 UUIDv4Generator。
+func (s *traceIDSource) set(generator TraceIDGenerator) {
+	if generator == nil {
+		generator = UUIDv4Generator()
+	}
+	s.generator.Store(&traceIDGeneratorRef{generator})
+}
 
 // GenerateTraceId 为 GLogger 类型实例提供生成全局唯一追踪ID的功能。
-// 这个方法利用 UUID 生成一个字符串，确保了每个调用生成的ID都是唯一的，
+// 默认利用 UUID 生成一个字符串，确保了每个调用生成的ID都是唯一的，
 // 有助于在分布式系统中跨服务追踪请求和日志。
+// 可通过 SetTraceIDGenerator 或 Config.TraceIDGenerator 改用 ULID、Snowflake 等格式。
 //
-// @receiver log *GLogger: GLogger 结构体的指针，尽管在此方法中未直接使用，
+// @receiver log *GLogger: GLogger 结构体的指针，子日志记录器与原实例使用同一个生成器。
 //
-//	但作为接收者表明此方法属于 GLogger 的实例方法。
-//
-// @return string: 返回一个全局唯一标识符（UUID）的字符串表示形式，用作追踪ID。
+// @return string: 返回一个全局唯一标识符的字符串表示形式，用作追踪ID。
 func (log *GLogger) GenerateTraceId() string {
-	// 使用uuid包生成一个新的UUID
-	return uuid.New().String()
+	traceIDs := log.traceIDs.Load()
+	if traceIDs == nil {
+		// 兼容直接构造的 GLogger：使用uuid包生成一个新的UUID
+		return uuid.New().String()
+	}
+	return traceIDs.generator.Load().Generate()
+}
+
+// SetTraceIDGenerator 替换 GenerateTraceId 使用的生成器，子日志记录器同样生效。
+// @param generator TraceIDGenerator: 新的生成器，为 nil 时恢复为 UUIDv4Generator。
+func (log *GLogger) SetTraceIDGenerator(generator TraceIDGenerator) {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if traceIDs := log.traceIDs.Load(); traceIDs != nil {
+		traceIDs.set(generator)
+		return
+	}
+	log.traceIDs.Store(newTraceIDSource(generator))
 }
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// TraceparentSampled 是 trace-flags 中表示已采样的标志位。
+const TraceparentSampled byte = 0x01
+
+// Traceparent 是 W3C Trace Context 的 traceparent 请求头，
+// 格式为 "00-<32 位 trace-id>-<16 位 parent-id>-<2 位 trace-flags>"，均为小写十六进制。
+type Traceparent struct {
+	TraceID  string // 整条调用链共享的追踪ID。
+	ParentID string // 调用方当前 span 的ID。
+	Flags    byte   // trace-flags，见 TraceparentSampled。
+}
+
+// ParseTraceparent 解析 traceparent 请求头，格式或取值不合法时返回错误。
+// 兼容更高版本的请求头：只读取前 55 个字符，其后的内容必须以 "-" 开头。
+func ParseTraceparent(header string) (Traceparent, error) {
+	invalid := fmt.Errorf("logger: invalid traceparent %q", header)
+	if len(header) < 55 || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return Traceparent{}, invalid
+	}
+	version := header[0:2]
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(header) != 55) ||
+		(len(header) > 55 && header[55] != '-') {
+		return Traceparent{}, invalid
+	}
+	tp := Traceparent{TraceID: header[3:35], ParentID: header[36:52]}
+	flags := header[53:55]
+	if !validHexID(tp.TraceID) || !validHexID(tp.ParentID) || !isLowerHex(flags) {
+		return Traceparent{}, invalid
+	}
+	b, _ := hex.DecodeString(flags)
+	tp.Flags = b[0]
+	return tp, nil
+}
+
+// validHexID 判断 id 是否为小写十六进制且不全为0，trace-id 与 parent-id 全为0时无效。
+func validHexID(id string) bool {
+	return isLowerHex(id) && strings.Trim(id, "0") != ""
+}
+
+// Sampled 返回调用方是否对该调用链进行了采样。
+func (t Traceparent) Sampled() bool {
+	return t.Flags&TraceparentSampled != 0
+}
+
+// String 按版本 00 的格式输出 traceparent 请求头。
+func (t Traceparent) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x", t.TraceID, t.ParentID, t.Flags)
+}
+
+// NewSpanID 生成一个随机的 16 位小写十六进制 span ID，用作 traceparent 的 parent-id。
+func NewSpanID() string {
+	var id [8]byte
+	for {
+		mustRandom(id[:])
+		if id != [8]byte{} {
+			return hex.EncodeToString(id[:])
+		}
+	}
+}
+
+// ToW3CTraceID 将任意格式的追踪ID转换为 W3C trace-id，使其可以放入 traceparent 与 B3 请求头。
+// W3C trace-id 原样返回；UUID 与 ULID 使用其 128 位的值，Snowflake 左侧补0；
+// 其余无法识别的ID（以及全0的值）取 SHA-256 摘要的前 16 字节，同一个ID总是得到相同的结果。
+func ToW3CTraceID(id string) string {
+	var b [16]byte
+	info, err := ParseTraceID(id)
+	switch {
+	case err != nil:
+	case info.Format == TraceIDW3C:
+		return id
+	case info.Format == TraceIDULID:
+		b, _ = decodeULID(id)
+	case info.Format == TraceIDSnowflake:
+		n, _ := strconv.ParseUint(id, 10, 64)
+		binary.BigEndian.PutUint64(b[8:], n)
+	default:
+		b = uuid.MustParse(id)
+	}
+	if b == [16]byte{} {
+		sum := sha256.Sum256([]byte(id))
+		copy(b[:], sum[:16])
+	}
+	return hex.EncodeToString(b[:])
+}
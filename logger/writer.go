@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// lineWriter 将写入的内容按行记录为日志。
+type lineWriter struct {
+	log   *zap.Logger
+	level zapcore.Level
+}
+
+// Writer 返回将每一行写入内容记录为一条 level 级别日志的 io.Writer，
+// 用于接管标准库 log、gin.DefaultWriter 等只接受 io.Writer 的输出。
+// 行尾的换行符会被去掉，空行被忽略；这类输出无法确定真实的调用位置，因此不记录 caller。
+// @param level string: 日志级别，大小写不敏感，无法识别时使用 info。
+// @return io.Writer: 按行记录日志的写入器，可在多个 goroutine 中并发使用。
+func (log *GLogger) Writer(level string) io.Writer {
+	return &lineWriter{log: log.base().WithOptions(zap.WithCaller(false)), level: GetLogLevel(level)}
+}
+
+// Write 将 p 按行记录为日志，总是返回 len(p)。
+func (w *lineWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			continue
+		}
+		if ce := w.log.Check(w.level, string(line)); ce != nil {
+			ce.Write()
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,165 @@
+// Package logtest 提供用于单元测试的 GLogger：日志写入内存，可按级别、消息与字段筛选并断言，
+// 无需读取日志文件或标准输出。
+//
+// 示例：
+//
+//	func TestHandler(t *testing.T) {
+//		logs := logtest.SetDefault(t)
+//		// ... 调用通过 monophonic.Default 记录日志的代码
+//		logs.FilterLevel("error").AssertCount(t, 1)
+//		logs.FilterLogger("http").AssertLogged(t, "[Receive]/ping")
+//	}
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Entry 是捕获的一条日志，通过 ContextMap 读取其全部字段。
+type Entry = observer.LoggedEntry
+
+// Logs 是捕获的日志，可在多个 goroutine 中并发写入与读取。
+// New 与 NewTB 返回的 Logs 会持续记录之后的日志，Filter 系列方法返回的则是筛选时的快照。
+type Logs struct {
+	observed *observer.ObservedLogs
+}
+
+// New 创建将全部级别的日志写入内存的 GLogger，返回的 Logs 用于读取捕获的日志。
+// 可通过 GLogger.SetLogLevel 限制捕获的级别。
+func New() (*logger.GLogger, *Logs) {
+	core, observed := observer.New(zapcore.DebugLevel)
+	return logger.NewGLoggerFromCore(core), &Logs{observed: observed}
+}
+
+// NewTB 与 New 相同，但同时以控制台格式通过 t.Log 输出日志，只在测试失败或使用 -v 时显示。
+// 测试结束后不能再记录日志，使用异步 goroutine 记录日志时需在测试结束前等待其退出。
+func NewTB(t testing.TB) (*logger.GLogger, *Logs) {
+	core, observed := observer.New(zapcore.DebugLevel)
+	tb := zaptest.NewLogger(t, zaptest.Level(zapcore.DebugLevel)).Core()
+	return logger.NewGLoggerFromCore(zapcore.NewTee(core, tb)), &Logs{observed: observed}
+}
+
+// SetDefault 以 NewTB 创建的 GLogger 替换 monophonic.Default，测试结束时自动恢复，
+// 用于测试 middleware、response 等通过 monophonic.Default 记录日志的代码。
+// 由于替换的是全局实例，调用它的测试不能使用 t.Parallel。
+func SetDefault(t testing.TB) *Logs {
+	glogger, logs := NewTB(t)
+	t.Cleanup(monophonic.SetDefault(glogger))
+	return logs
+}
+
+// Len 返回日志条数。
+func (l *Logs) Len() int {
+	return l.observed.Len()
+}
+
+// All 按记录顺序返回全部日志。
+func (l *Logs) All() []Entry {
+	return l.observed.All()
+}
+
+// TakeAll 返回并清空全部日志，便于在同一个测试中分段断言。
+func (l *Logs) TakeAll() []Entry {
+	return l.observed.TakeAll()
+}
+
+// Messages 按记录顺序返回全部日志的消息。
+func (l *Logs) Messages() []string {
+	entries := l.observed.All()
+	messages := make([]string, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// Filter 返回 keep 返回 true 的日志。
+func (l *Logs) Filter(keep func(Entry) bool) *Logs {
+	return &Logs{observed: l.observed.Filter(keep)}
+}
+
+// FilterLevel 返回级别恰好为 level 的日志，level 大小写不敏感，无法识别时视为 info。
+func (l *Logs) FilterLevel(level string) *Logs {
+	return &Logs{observed: l.observed.FilterLevelExact(logger.GetLogLevel(level))}
+}
+
+// FilterMessage 返回消息等于 msg 的日志。
+func (l *Logs) FilterMessage(msg string) *Logs {
+	return &Logs{observed: l.observed.FilterMessage(msg)}
+}
+
+// FilterMessageSnippet 返回消息包含 snippet 的日志。
+func (l *Logs) FilterMessageSnippet(snippet string) *Logs {
+	return &Logs{observed: l.observed.FilterMessageSnippet(snippet)}
+}
+
+// FilterLogger 返回日志记录器名称等于 name 的日志，如 "http"、"gorm"。
+func (l *Logs) FilterLogger(name string) *Logs {
+	return l.Filter(func(entry Entry) bool { return entry.LoggerName == name })
+}
+
+// FilterField 返回带有与 field 完全相同字段的日志，如 zap.String("traceId", id)。
+func (l *Logs) FilterField(field zapcore.Field) *Logs {
+	return &Logs{observed: l.observed.FilterField(field)}
+}
+
+// FilterFieldKey 返回带有名为 key 的字段的日志。
+func (l *Logs) FilterFieldKey(key string) *Logs {
+	return &Logs{observed: l.observed.FilterFieldKey(key)}
+}
+
+// AssertCount 断言日志条数为 n，不满足时标记测试失败并输出全部日志。
+func (l *Logs) AssertCount(t testing.TB, n int) bool {
+	t.Helper()
+	if got := l.Len(); got != n {
+		t.Errorf("logtest: expected %d entries, got %d:\n%s", n, got, l)
+		return false
+	}
+	return true
+}
+
+// AssertEmpty 断言没有任何日志。
+func (l *Logs) AssertEmpty(t testing.TB) bool {
+	t.Helper()
+	return l.AssertCount(t, 0)
+}
+
+// AssertLogged 断言至少有一条消息等于 msg 的日志。
+func (l *Logs) AssertLogged(t testing.TB, msg string) bool {
+	t.Helper()
+	if l.FilterMessage(msg).Len() == 0 {
+		t.Errorf("logtest: no entry with message %q in:\n%s", msg, l)
+		return false
+	}
+	return true
+}
+
+// AssertNotLogged 断言没有消息等于 msg 的日志。
+func (l *Logs) AssertNotLogged(t testing.TB, msg string) bool {
+	t.Helper()
+	if matched := l.FilterMessage(msg); matched.Len() != 0 {
+		t.Errorf("logtest: unexpected entry with message %q:\n%s", msg, matched)
+		return false
+	}
+	return true
+}
+
+// String 每行一条地列出全部日志的级别、名称、消息与字段，用于断言失败时的输出。
+func (l *Logs) String() string {
+	var b strings.Builder
+	for _, entry := range l.observed.All() {
+		fmt.Fprintf(&b, "\t%s\t%s\t%s\t%v\n", entry.Level, entry.LoggerName, entry.Message, entry.ContextMap())
+	}
+	if b.Len() == 0 {
+		return "\t(no entries)\n"
+	}
+	return b.String()
+}
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/response"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TagLevel 定义日志级别管理相关日志的标签。
+const TagLevel = "[LogLevel]"
+
+// DefaultLoggerName 是 monophonic.Default 在级别管理接口中使用的名称。
+const DefaultLoggerName = "default"
+
+// OperatorHeader 是级别管理接口读取操作人的请求头，缺省时使用客户端IP。
+const OperatorHeader = "X-Operator"
+
+// LevelRequest 是修改日志级别的请求体。
+//
+// 属性说明：
+//   - Logger：要修改的日志记录器名称，为空时修改 monophonic.Default。
+//   - Level：新的日志级别，大小写不敏感。
+//   - TTL：可选的有效期（如 "10m"），到期后自动恢复为修改前的级别。
+type LevelRequest struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level" binding:"required"`
+	TTL    string `json:"ttl"`
+}
+
+// pendingRevert 记录一次带有效期的修改，到期后恢复为 level；inherit 为 true 时改为恢复沿用上级的级别。
+type pendingRevert struct {
+	level   string
+	inherit bool
+	timer   *time.Timer
+}
+
+// levelInheritor 是可以区分单独设置的级别与沿用上级的级别的日志记录器，如 logger.GLogger。
+type levelInheritor interface {
+	InheritsLogLevel() bool
+	ResetLogLevel()
+}
+
+// builtinLoggerNames 是本包中间件使用的日志记录器名称，无需注册即可管理，总是指向当前 monophonic.Default 的同名子日志记录器。
+var builtinLoggerNames = []string{HTTPLoggerName, HTTPClientLoggerName, GormLoggerName}
+
+// LevelAdmin 提供查看与动态修改日志级别的 Gin 处理器。
+// monophonic.Default 总是以 DefaultLoggerName 出现，GinLogger、Transport 与 GormLogger 使用的
+// "http"、"http.client"、"gorm" 无需注册即可管理，其他日志记录器需通过 Register 注册。
+type LevelAdmin struct {
+	mu      sync.Mutex
+	loggers map[string]logger.LogInterface
+	pending map[string]*pendingRevert
+}
+
+// NewLevelAdmin 创建一个 LevelAdmin 实例。
+func NewLevelAdmin() *LevelAdmin {
+	return &LevelAdmin{
+		loggers: map[string]logger.LogInterface{},
+		pending: map[string]*pendingRevert{},
+	}
+}
+
+// Register 以 name 注册一个可被管理的日志记录器，重复注册会覆盖之前的记录器。
+func (a *LevelAdmin) Register(name string, l logger.LogInterface) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.loggers[name] = l
+}
+
+// Routes 在 group 上注册 GET 与 PUT 两个处理器。
+//
+// 示例：
+//
+//	admin := middleware.NewLevelAdmin()
+//	admin.Register("db", dbLogger)
+//	admin.Routes(engine.Group("/admin/log/level"))
+func (a *LevelAdmin) Routes(group gin.IRoutes) {
+	group.GET("", a.Get)
+	group.PUT("", a.Put)
+}
+
+// Get 返回所有日志记录器当前生效的级别，可通过查询参数 logger 只查看其中一个。
+func (a *LevelAdmin) Get(c *gin.Context) {
+	if name := c.Query("logger"); name != "" {
+		l, ok := a.lookup(name)
+		if !ok {
+			response.Error(c, http.StatusNotFound, nil, "unknown logger "+name)
+			return
+		}
+		response.OK(c, gin.H{name: l.GetLogLevel()}, "ok")
+		return
+	}
+	response.OK(c, a.levels(), "ok")
+}
+
+// Put 按请求体修改日志级别，并记录操作人与修改时间。
+func (a *LevelAdmin) Put(c *gin.Context) {
+	var req LevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, err, "invalid request body")
+		return
+	}
+	if req.Logger == "" {
+		req.Logger = DefaultLoggerName
+	}
+	if _, err := zapcore.ParseLevel(req.Level); err != nil {
+		response.Error(c, http.StatusBadRequest, err, "invalid log level")
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			response.Error(c, http.StatusBadRequest, err, "invalid ttl")
+			return
+		}
+	}
+
+	l, ok := a.lookup(req.Logger)
+	if !ok {
+		response.Error(c, http.StatusNotFound, nil, "unknown logger "+req.Logger)
+		return
+	}
+
+	operator := c.GetHeader(OperatorHeader)
+	if operator == "" {
+		operator = c.ClientIP()
+	}
+	a.set(req.Logger, l, req.Level, ttl, operator)
+	response.OK(c, gin.H{req.Logger: l.GetLogLevel()}, "ok")
+}
+
+// set 修改级别并安排到期恢复。已有未到期的恢复任务时，恢复目标保持为最初的级别。
+func (a *LevelAdmin) set(name string, l logger.LogInterface, level string, ttl time.Duration, operator string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous := l.GetLogLevel()
+	revert := &pendingRevert{level: previous}
+	if inheritor, ok := l.(levelInheritor); ok {
+		revert.inherit = inheritor.InheritsLogLevel()
+	}
+	if p, ok := a.pending[name]; ok {
+		p.timer.Stop()
+		revert = &pendingRevert{level: p.level, inherit: p.inherit}
+		delete(a.pending, name)
+	}
+
+	l.SetLogLevel(level)
+	fields := []zapcore.Field{
+		zap.String("logger", name),
+		zap.String("from", previous),
+		zap.String("to", l.GetLogLevel()),
+		zap.String("operator", operator),
+		zap.Time("at", time.Now()),
+	}
+	if ttl > 0 {
+		fields = append(fields, zap.Duration("ttl", ttl))
+	}
+	monophonic.Default().Info(TagLevel+" level changed", fields...)
+
+	if ttl <= 0 {
+		return
+	}
+	revert.timer = time.AfterFunc(ttl, func() { a.revert(name, l, revert) })
+	a.pending[name] = revert
+}
+
+// revert 在有效期结束后恢复级别，若该任务已被新的修改取代则不做任何事。
+func (a *LevelAdmin) revert(name string, l logger.LogInterface, p *pendingRevert) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pending[name] != p {
+		return
+	}
+	delete(a.pending, name)
+
+	previous := l.GetLogLevel()
+	if inheritor, ok := l.(levelInheritor); ok && p.inherit {
+		// 修改前沿用上级的级别，恢复时删除单独设置的级别，使之继续跟随全局级别与 Reload
+		inheritor.ResetLogLevel()
+	} else {
+		l.SetLogLevel(p.level)
+	}
+	monophonic.Default().Info(TagLevel+" level reverted",
+		zap.String("logger", name),
+		zap.String("from", previous),
+		zap.String("to", l.GetLogLevel()),
+		zap.String("operator", "ttl"),
+		zap.Time("at", time.Now()),
+	)
+}
+
+// lookup 按名称查找日志记录器，DefaultLoggerName 与内置名称总是指向当前的 monophonic.Default 及其子日志记录器。
+func (a *LevelAdmin) lookup(name string) (logger.LogInterface, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if l, ok := a.loggers[name]; ok {
+		return l, true
+	}
+	if name == DefaultLoggerName {
+		return monophonic.Default(), true
+	}
+	if slices.Contains(builtinLoggerNames, name) {
+		return monophonic.Default().Named(name), true
+	}
+	return nil, false
+}
+
+// levels 返回所有日志记录器当前生效的级别。
+func (a *LevelAdmin) levels() map[string]string {
+	a.mu.Lock()
+	loggers := make(map[string]logger.LogInterface, len(a.loggers)+1)
+	for name, l := range a.loggers {
+		loggers[name] = l
+	}
+	a.mu.Unlock()
+
+	if _, ok := loggers[DefaultLoggerName]; !ok {
+		loggers[DefaultLoggerName] = monophonic.Default()
+	}
+	for _, name := range builtinLoggerNames {
+		if _, ok := loggers[name]; !ok {
+			loggers[name] = monophonic.Default().Named(name)
+		}
+	}
+	levels := make(map[string]string, len(loggers))
+	for name, l := range loggers {
+		levels[name] = l.GetLogLevel()
+	}
+	return levels
+}
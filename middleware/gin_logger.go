@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/uniharmonic/monophonic/logger"
 	"github.com/uniharmonic/monophonic/response"
 
 	"github.com/gin-gonic/gin"
@@ -18,35 +19,39 @@ import (
 // TagDefault 定义了日志记录中的默认接收标签，用于标记接收到请求的记录。
 const TagDefault = "[Receive]"
 
+// HTTPLoggerName 是 GinLogger 使用的日志记录器名称，可通过 "http=info" 等配置单独设置其级别。
+const HTTPLoggerName = "http"
+
 // maxMemory 定义了处理请求体时允许的最大内存大小，单位为字节。
 const maxMemory = 32 << 20 // 32MB
 
 // GinLogger 返回一个Gin中间件处理器，用于记录请求的详细日志信息。
-// 每当请求到达时，此中间件会先记录请求的初步信息。
+// 请求到达时沿用 RequestID 中间件设置的请求ID，未注册时为其生成追踪ID，并写入请求的 context.Context，
+// 后续通过 Ctx 系列方法记录的日志（如 response.OK、GormLogger）都会带上同一个追踪ID。
+// 同时为请求开始一个名为 "<method> <route>" 的 span，处理过程中通过 monophonic.Start 开始的 span
+// 与 GormLogger 记录的查询都嵌套在其中，可以通过 logger.SpansFromContext 取出。
 func GinLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		monophonic.Default.Info(TagDefault+c.FullPath(), GetFields(c)...)
+		response.RequestID(c)
+		ctx, end := logger.StartSpan(c.Request.Context(), c.Request.Method+" "+c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+		fields := GetFields(c)
+		span := end(zap.Int("status", c.Writer.Status()))
+		fields = append(fields, zap.String("spanId", span.SpanID))
+		monophonic.Default().Named(HTTPLoggerName).InfoCtx(c.Request.Context(), TagDefault+c.FullPath(), fields...)
 	}
 }
 
 // GetFields 根据Gin的上下文信息构建日志字段切片。
 // 这些字段包括请求处理耗时、响应状态码、请求方法、路径、查询参数、客户端IP、User-Agent、错误信息等。
-// 若上下文中存在"result"键且其值不为空，则还会添加追踪ID字段。
+// 追踪ID保存在请求的 context.Context 中，由 InfoCtx 等方法自动附加，因此不在此处返回。
 func GetFields(c *gin.Context) []zapcore.Field {
 	start := time.Now()
 	c.Next() // 继续执行后续的处理函数
 	cost := time.Since(start).Milliseconds()
 
-	fields := []zapcore.Field{}
-
-	// 尝试从上下文中提取并添加追踪ID
-	if res, ok := c.Get("result"); ok && res != nil {
-		traceID := res.(*response.Response).TraceID
-		fields = append(fields, zap.String("traceId", traceID))
-	}
-
-	// 添加其他标准日志字段
-	return append(fields,
+	// 添加标准日志字段
+	return []zapcore.Field{
 		zap.Int("status", c.Writer.Status()),                                 // HTTP响应状态码
 		zap.String("method", c.Request.Method),                               // 请求方法
 		zap.String("path", c.Request.URL.Path),                               // 请求路径
@@ -55,7 +60,7 @@ func GetFields(c *gin.Context) []zapcore.Field {
 		zap.String("user-agent", c.Request.UserAgent()),                      // 用户代理信息
 		zap.String("errors", c.Errors.ByType(gin.ErrorTypePrivate).String()), // 私有错误信息
 		zap.Int64("cost", cost),                                              // 请求处理耗时（毫秒）
-	)
+	}
 }
 
 // getParams 根据不同的请求类型解析并返回请求参数。
@@ -13,6 +13,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// TagRecovery 定义了日志记录中的恢复标签，用于标记从 panic 中恢复的记录。
+const TagRecovery = "[Recovery from panic]"
+
 // GinRecovery 是一个 Gin 中间件函数，用于捕获并恢复项目中可能出现的 panic 错误，
 // 确保服务在遇到运行时错误时仍能保持稳定运行。它还提供了日志记录功能，并可选地记录调用栈信息。
 //
@@ -41,7 +44,7 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 
 				if brokenPipe {
 					// 对于断开的连接，仅记录错误和请求信息，不尝试写入响应状态
-					monophonic.Default.Error(c.Request.URL.Path,
+					monophonic.Default().Error(c.Request.URL.Path,
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
 					)
@@ -58,7 +61,7 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 				if stack {
 					logFields = append(logFields, zap.String("stack", string(debug.Stack())))
 				}
-				monophonic.Default.Error("[Recovery from panic]", logFields...)
+				monophonic.Default().Error(TagRecovery, logFields...)
 
 				// 终止当前请求并返回内部服务器错误状态码
 				c.AbortWithStatus(http.StatusInternalServerError)
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/response"
+)
+
+const (
+	// HeaderRequestID 是传递请求ID的请求头与响应头。
+	HeaderRequestID = "X-Request-ID"
+	// HeaderTraceparent 是 W3C Trace Context 的 traceparent 请求头。
+	HeaderTraceparent = "traceparent"
+)
+
+// maxRequestIDLength 是接受的外部请求ID的最大长度，过长的ID会被忽略并重新生成。
+const maxRequestIDLength = 128
+
+// RequestID 返回一个Gin中间件处理器，为每个请求确定唯一的请求ID，应注册在 GinLogger 之前。
+// 依次读取请求头 X-Request-ID 与 traceparent 中的 trace-id，均不存在或不合法时通过
+// monophonic.Default().GenerateTraceId 生成。请求ID会写入 gin.Context 与请求的 context.Context，
+// 并通过响应头 X-Request-ID 返回，GinLogger、response.OK/Error 与 GormLogger 的日志都会使用该ID。
+// 合法的 traceparent 还会通过 logger.WithTraceparent 保存，供 Transport 向下游继续传播。
+//
+// 示例：
+//
+//	engine.Use(middleware.RequestID(), middleware.GinLogger())
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tp, err := logger.ParseTraceparent(c.GetHeader(HeaderTraceparent)); err == nil {
+			c.Request = c.Request.WithContext(logger.WithTraceparent(c.Request.Context(), tp))
+		}
+		id := inboundRequestID(c)
+		if id == "" {
+			id = monophonic.Default().GenerateTraceId()
+		}
+		response.SetRequestID(c, id)
+		c.Header(HeaderRequestID, id)
+	}
+}
+
+// inboundRequestID 返回调用方传入的请求ID，不存在或不合法时返回空字符串。
+func inboundRequestID(c *gin.Context) string {
+	if id := c.GetHeader(HeaderRequestID); validRequestID(id) {
+		return id
+	}
+	if tp, err := logger.ParseTraceparent(c.GetHeader(HeaderTraceparent)); err == nil {
+		return tp.TraceID
+	}
+	return ""
+}
+
+// validRequestID 判断外部传入的请求ID是否可以直接使用：非空、不超过 maxRequestIDLength，
+// 且只包含可见的 ASCII 字符，避免将换行等控制字符写入日志与响应头。
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/uniharmonic/monophonic"
+	"go.uber.org/zap"
+)
+
+// TagShutdown 是优雅关闭相关日志的消息前缀。
+const TagShutdown = "[Shutdown]"
+
+// ListenAndServe 启动 srv 并阻塞，直到收到退出信号或服务启动失败。
+// 收到信号后，在 timeout 内等待正在处理的请求完成并关闭 srv，记录关闭耗时，
+// 最后写出并关闭 monophonic.Default，保证退出前的日志不会丢失。
+//
+// Parameters:
+// - srv (*http.Server): 待启动的服务，通常以 gin.Engine 作为 Handler。
+// - timeout (time.Duration): 等待请求完成的最长时间，不大于0时使用10秒。
+// - signals (...os.Signal): 触发关闭的信号，未指定时使用 SIGINT 与 SIGTERM。
+//
+// Returns:
+// - error: 服务启动失败、关闭超时或日志关闭失败时返回的错误，正常关闭时返回 nil。
+//
+// 示例：
+//
+//	srv := &http.Server{Addr: ":8080", Handler: engine}
+//	if err := middleware.ListenAndServe(srv, 5*time.Second); err != nil {
+//		os.Exit(1)
+//	}
+func ListenAndServe(srv *http.Server, timeout time.Duration, signals ...os.Signal) error {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	// 先注册信号再启动服务，避免启动过程中收到的信号直接终止进程
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	log := monophonic.Default()
+	select {
+	case err := <-serveErr:
+		// 服务未能启动或被其他地方关闭，此时无需再等待请求完成
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		} else {
+			log.Error(TagShutdown+" server stopped unexpectedly", zap.String("addr", srv.Addr), zap.Error(err))
+		}
+		return errors.Join(err, log.Close())
+	case <-ctx.Done():
+	}
+	stop() // 恢复默认的信号处理，再次收到信号时立即退出
+
+	log.Info(TagShutdown+" signal received, shutting down", zap.String("addr", srv.Addr), zap.Duration("timeout", timeout))
+	start := time.Now()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Error(TagShutdown+" server shutdown failed", zap.Duration("duration", time.Since(start)), zap.Error(err))
+	} else {
+		log.Info(TagShutdown+" server stopped", zap.Duration("duration", time.Since(start)))
+	}
+	return errors.Join(err, log.Close())
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+)
+
+// GinDebugLoggerName 是接管 gin 调试输出后使用的日志记录器名称，可通过 "gin=warn" 等配置单独设置其级别。
+const GinDebugLoggerName = "gin"
+
+// RedirectGinWriters 将 gin.DefaultWriter 与 gin.DefaultErrorWriter 重定向到 monophonic.Default，
+// 路由注册等调试输出按 level 记录，错误输出按 errorLevel 记录，每行一条日志。
+// 需要在创建 gin.Engine 之前调用；返回的函数用于恢复原来的输出。
+//
+// 示例：
+//
+//	restore := middleware.RedirectGinWriters("debug", "error")
+//	defer restore()
+//	engine := gin.New()
+func RedirectGinWriters(level string, errorLevel string) (restore func()) {
+	writer, errorWriter := gin.DefaultWriter, gin.DefaultErrorWriter
+	gin.DefaultWriter = monophonic.DefaultWriter(GinDebugLoggerName, level)
+	gin.DefaultErrorWriter = monophonic.DefaultWriter(GinDebugLoggerName, errorLevel)
+	return func() {
+		gin.DefaultWriter, gin.DefaultErrorWriter = writer, errorWriter
+	}
+}
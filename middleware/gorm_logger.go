@@ -5,14 +5,44 @@ import (
 	"errors"
 	"fmt"
 	"github.com/uniharmonic/monophonic"
+	monologger "github.com/uniharmonic/monophonic/logger"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const TAG = "[GORM]"
 
+// GormLoggerName 是 GORM 日志使用的日志记录器名称，可通过 "gorm=warn" 等配置单独设置其级别。
+const GormLoggerName = "gorm"
+
+// skipGormCallers 保证 GORM 的栈帧只登记一次。
+var skipGormCallers sync.Once
+
+// gormChild 记录由某个默认日志记录器派生的 GORM 子日志记录器。
+type gormChild struct {
+	parent *monologger.GLogger
+	log    monologger.LogInterface
+}
+
+// gormCache 缓存 GORM 子日志记录器，默认日志记录器被替换后重新派生。
+var gormCache atomic.Pointer[gormChild]
+
+// gormLog 返回 GORM 日志使用的子日志记录器，默认日志记录器未变化时复用缓存。
+func gormLog() monologger.LogInterface {
+	parent := monophonic.Default()
+	if c := gormCache.Load(); c != nil && c.parent == parent {
+		return c.log
+	}
+	log := parent.Named(GormLoggerName)
+	gormCache.Store(&gormChild{parent: parent, log: log})
+	return log
+}
+
 //type GormLoggerInterface interface {
 //	LogMode(level logger.LogLevel) GormLoggerInterface
 //	Info(context.Context, string, ...interface{})
@@ -25,38 +55,53 @@ type GormLogger struct {
 	SlowThreshold time.Duration
 }
 
+// NewGormLogger 创建 GORM 日志记录器，并将 GORM 的栈帧登记为日志辅助函数，
+// 使 caller 指向执行查询的业务代码。直接构造的 GormLogger 不会登记，caller 将指向 GORM 内部。
+// @param slowThreshold time.Duration: 慢查询阈值，为0时不记录慢查询。
+// @return *GormLogger: GORM 日志记录器。
+func NewGormLogger(slowThreshold time.Duration) *GormLogger {
+	skipGormCallers.Do(func() {
+		monologger.SkipCallerPackages("gorm.io/")
+	})
+	return &GormLogger{SlowThreshold: slowThreshold}
+}
+
 func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
 	switch level {
 	case logger.Silent:
-		monophonic.Default.SetLogLevel("fatal")
+		gormLog().SetLogLevel("fatal")
 	case logger.Info:
-		monophonic.Default.SetLogLevel("debug")
+		gormLog().SetLogLevel("debug")
 	case logger.Warn:
-		monophonic.Default.SetLogLevel("warn")
+		gormLog().SetLogLevel("warn")
 	case logger.Error:
-		monophonic.Default.SetLogLevel("error")
+		gormLog().SetLogLevel("error")
 	default:
-		monophonic.Default.SetLogLevel("debug")
+		gormLog().SetLogLevel("debug")
 	}
 	return l
 }
 
 func (l *GormLogger) Info(ctx context.Context, str string, args ...interface{}) {
+	monologger.Helper()
 	msg := fmt.Sprintf("%s Info: %s", TAG, fmt.Sprintf(str, args...))
-	monophonic.Default.Info(msg)
+	gormLog().InfoCtx(ctx, msg)
 }
 
 func (l *GormLogger) Warn(ctx context.Context, str string, args ...interface{}) {
+	monologger.Helper()
 	msg := fmt.Sprintf("%s Warn: %s", TAG, fmt.Sprintf(str, args...))
-	monophonic.Default.Warn(msg)
+	gormLog().WarnCtx(ctx, msg)
 }
 
 func (l *GormLogger) Error(ctx context.Context, str string, args ...interface{}) {
+	monologger.Helper()
 	msg := fmt.Sprintf("%s Error: %s", TAG, fmt.Sprintf(str, args...))
-	monophonic.Default.Error(msg)
+	gormLog().ErrorCtx(ctx, msg)
 }
 
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	monologger.Helper()
 	// 获取运行时间
 	elapsed := time.Since(begin)
 	// 获取 SQL 请求和返回条数
@@ -67,30 +112,44 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql
 		zap.Float64("time", elapsed.Seconds()),
 		zap.Int64("rows", rows),
 	}
+	// 在请求的追踪中记录本次查询的 span
+	span := monologger.RecordSpan(ctx, gormSpanName(sql), begin, begin.Add(elapsed), zap.String("sql", sql), zap.Int64("rows", rows))
+	if span.SpanID != "" {
+		logFields = append(logFields, zap.String("spanId", span.SpanID))
+	}
 	// Gorm 错误
 	if err != nil {
 		// 记录未找到的错误使用 warning 等级
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			msg := fmt.Sprintf("%s %s", TAG, "ErrRecordNotFound")
-			monophonic.Default.Warn(msg, logFields...)
+			gormLog().WarnCtx(ctx, msg, logFields...)
 		} else {
 			msg := fmt.Sprintf("%s %s", TAG, "Error")
 			// 其他错误使用 error 等级
 			logFields = append(logFields, zap.Error(err))
-			monophonic.Default.Error(msg, logFields...)
+			gormLog().ErrorCtx(ctx, msg, logFields...)
 		}
 	} else if l.SlowThreshold != 0 && elapsed > l.SlowThreshold {
 		msg := fmt.Sprintf("%s %s", TAG, "Slow Log")
-		monophonic.Default.Warn(msg, logFields...)
+		gormLog().WarnCtx(ctx, msg, logFields...)
 	} else {
 		msg := fmt.Sprintf("%s %s", TAG, "Query")
-		monophonic.Default.Debug(msg, logFields...)
+		gormLog().DebugCtx(ctx, msg, logFields...)
+	}
+}
+
+// gormSpanName 返回 SQL 查询的 span 名称，如 "gorm.select"。
+func gormSpanName(sql string) string {
+	verb, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	if verb == "" {
+		return GormLoggerName
 	}
+	return GormLoggerName + "." + strings.ToLower(verb)
 }
 
 func GetGormConfig(level string) *gorm.Config {
-	gormLogger := &GormLogger{}
-	monophonic.Default.SetLogLevel(level)
+	gormLogger := NewGormLogger(0)
+	gormLog().SetLogLevel(level)
 	return &gorm.Config{
 		Logger: gormLogger,
 	}
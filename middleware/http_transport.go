@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TagClient 定义了日志记录中的客户端请求标签，用于标记向下游发起的请求。
+const TagClient = "[Client]"
+
+// HTTPClientLoggerName 是 Transport 使用的日志记录器名称，未单独设置时沿用 "http" 的级别。
+const HTTPClientLoggerName = HTTPLoggerName + ".client"
+
+// B3 传播使用的请求头。
+const (
+	HeaderB3TraceID      = "X-B3-TraceId"
+	HeaderB3SpanID       = "X-B3-SpanId"
+	HeaderB3ParentSpanID = "X-B3-ParentSpanId"
+	HeaderB3Sampled      = "X-B3-Sampled"
+	HeaderB3             = "b3"
+)
+
+// Propagation 是 Transport 向下游传播追踪ID的方式，可以按位组合。
+type Propagation int
+
+const (
+	PropagateTraceparent Propagation = 1 << iota // W3C traceparent 请求头。
+	PropagateRequestID                           // X-Request-ID 请求头，值为原始的追踪ID。
+	PropagateB3                                  // B3 多请求头格式（X-B3-TraceId 等）。
+	PropagateB3Single                            // B3 单请求头格式（b3）。
+
+	// DefaultPropagation 是 Transport.Propagation 为0时使用的传播方式。
+	DefaultPropagation = PropagateTraceparent | PropagateRequestID
+)
+
+/*
+Transport 是记录日志并传播追踪ID的 http.RoundTripper，用于服务之间的调用，与 RequestID 中间件配合使用。
+
+发起请求前，从请求的 context.Context 中读取追踪ID（不存在时生成一个），按 Propagation 写入请求头，
+请求已经带有的同名请求头保持不变。本次调用的父 span 依次取 OpenTelemetry 的当前 span、
+通过 StartSpan 或 GLogger.Start 开始的内置 span，以及调用方 traceparent 中的 parent-id；请求结束后通过 monophonic.Default() 名为 "http.client" 的子日志记录器
+记录请求方法、URL、查询参数、状态码与耗时，与 GinLogger 一样经过日志记录器配置的脱敏规则，URL 中的密码会被隐藏。
+
+属性说明：
+  - Base：实际发送请求的 http.RoundTripper，为 nil 时使用 http.DefaultTransport。
+  - Propagation：追踪ID的传播方式，为0时使用 DefaultPropagation。
+
+示例：
+
+	client := &http.Client{Transport: &middleware.Transport{Propagation: middleware.PropagateTraceparent | middleware.PropagateB3}}
+	req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, "http://orders/api/v1/orders", nil)
+	resp, err := client.Do(req)
+*/
+type Transport struct {
+	Base        http.RoundTripper
+	Propagation Propagation
+}
+
+// RoundTrip 传播追踪ID、发送请求并记录日志，不会修改传入的请求。
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	traceID := logger.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = monophonic.Default().GenerateTraceId()
+		ctx = logger.WithTraceID(ctx, traceID)
+	}
+	tp, parentID := parentTraceparent(ctx, traceID)
+	tp.ParentID = logger.NewSpanID()
+
+	out := req.Clone(ctx)
+	t.inject(out.Header, traceID, tp, parentID)
+
+	start := time.Now()
+	resp, err := t.base().RoundTrip(out)
+	fields := []zapcore.Field{
+		zap.String("method", req.Method),
+		zap.String("url", redactedURL(req.URL)),
+		zap.String("query", req.URL.RawQuery),
+		zap.String("spanId", tp.ParentID),
+		zap.Int64("cost", time.Since(start).Milliseconds()),
+	}
+	// 消息按下游主机区分，与 GinLogger 按路由区分类似，便于采样与检索
+	msg := TagClient + req.URL.Host
+	log := monophonic.Default().Named(HTTPClientLoggerName)
+	switch {
+	case err != nil:
+		log.ErrorCtx(ctx, msg, append(fields, zap.Error(err))...)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		log.WarnCtx(ctx, msg, append(fields, zap.Int("status", resp.StatusCode))...)
+	default:
+		log.InfoCtx(ctx, msg, append(fields, zap.Int("status", resp.StatusCode))...)
+	}
+	return resp, err
+}
+
+// parentTraceparent 返回本次调用所在的追踪与父 span 的ID，本次调用作为下游的父 span。
+// OpenTelemetry 的 span 优先，其次沿用调用方 traceparent 中的 trace-id 与采样标志，
+// 并以当前的内置 span 作为父 span，二者都不存在时以追踪ID生成 trace-id。
+func parentTraceparent(ctx context.Context, traceID string) (logger.Traceparent, string) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return logger.Traceparent{TraceID: sc.TraceID().String(), Flags: byte(sc.TraceFlags())}, sc.SpanID().String()
+	}
+	tp := logger.Traceparent{TraceID: logger.ToW3CTraceID(traceID), Flags: logger.TraceparentSampled}
+	var parentID string
+	if inbound, ok := logger.TraceparentFromContext(ctx); ok {
+		tp.TraceID, tp.Flags, parentID = inbound.TraceID, inbound.Flags, inbound.ParentID
+	}
+	if spanID := logger.SpanIDFromContext(ctx); spanID != "" {
+		parentID = spanID
+	}
+	return tp, parentID
+}
+
+// base 返回实际发送请求的 http.RoundTripper。
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// inject 按 Propagation 写入追踪相关的请求头，已经存在的请求头保持不变。
+func (t *Transport) inject(header http.Header, traceID string, tp logger.Traceparent, parentID string) {
+	propagation := t.Propagation
+	if propagation == 0 {
+		propagation = DefaultPropagation
+	}
+	setIfAbsent := func(key, value string) {
+		if value != "" && header.Get(key) == "" {
+			header.Set(key, value)
+		}
+	}
+	sampled := "0"
+	if tp.Sampled() {
+		sampled = "1"
+	}
+	if propagation&PropagateTraceparent != 0 {
+		setIfAbsent(HeaderTraceparent, tp.String())
+	}
+	if propagation&PropagateRequestID != 0 {
+		setIfAbsent(HeaderRequestID, traceID)
+	}
+	if propagation&PropagateB3 != 0 {
+		setIfAbsent(HeaderB3TraceID, tp.TraceID)
+		setIfAbsent(HeaderB3SpanID, tp.ParentID)
+		setIfAbsent(HeaderB3ParentSpanID, parentID)
+		setIfAbsent(HeaderB3Sampled, sampled)
+	}
+	if propagation&PropagateB3Single != 0 {
+		b3 := tp.TraceID + "-" + tp.ParentID + "-" + sampled
+		if parentID != "" {
+			b3 += "-" + parentID
+		}
+		setIfAbsent(HeaderB3, b3)
+	}
+}
+
+// redactedURL 返回不含查询参数、并隐藏了密码的 URL，查询参数单独以 query 字段记录，与 GinLogger 一致。
+func redactedURL(u *url.URL) string {
+	stripped := *u
+	stripped.RawQuery = ""
+	stripped.ForceQuery = false
+	stripped.Fragment = ""
+	stripped.RawFragment = ""
+	return stripped.Redacted()
+}
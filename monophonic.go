@@ -1,14 +1,207 @@
 package monophonic
 
 import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
 	"github.com/uniharmonic/monophonic/logger"
+	"go.opentelemetry.io/otel/log"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"os"
 )
 
-// Default 是一个默认初始化的 GLogger 实例，方便全局访问。
-var Default = New("debug", "tmp/run.log")
+var (
+	defaultLogger atomic.Pointer[logger.GLogger] // 全局默认的 GLogger 实例。
+	defaultMu     sync.Mutex                     // 保证默认实例只被初始化一次。
+)
+
+// Default 返回全局默认的 GLogger 实例，方便全局访问。
+// 首次调用时才会创建实例：按 MONOPHONIC_* 环境变量配置（见 logger.ConfigFromEnv），
+// 未设置任何环境变量时只输出到标准输出，不会创建日志文件。
+// 如需自定义，请在首次使用前调用 SetDefault。可在多个 goroutine 中并发调用。
+// 早期版本中 Default 是包级变量，升级时将 Default.Info 改为 Default().Info、将对 Default 的赋值改为 SetDefault，见 CHANGELOG.md。
+func Default() *logger.GLogger {
+	if glogger := defaultLogger.Load(); glogger != nil {
+		return glogger
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if glogger := defaultLogger.Load(); glogger != nil {
+		return glogger
+	}
+	glogger, err := NewFromEnv()
+	if err != nil {
+		// 环境变量配置有误时退回到标准输出，并记录原因
+		glogger, _ = NewWithOptions(WithStdout("", logger.EncodingConsole))
+		glogger.Error("[Default] invalid environment config, falling back to stdout", zap.Error(err))
+	}
+	defaultLogger.Store(glogger)
+	return glogger
+}
+
+// SetDefault 替换全局默认的 GLogger 实例，可在多个 goroutine 中并发调用。
+// 返回的函数用于恢复为替换前的实例，便于在测试中临时替换。
+// 传入 nil 时，下一次调用 Default 会重新按环境变量创建实例。
+//
+// 示例：
+//
+//	restore := monophonic.SetDefault(monophonic.New("info", "tmp/run.log"))
+//	defer restore()
+func SetDefault(glogger *logger.GLogger) (restore func()) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	previous := defaultLogger.Swap(glogger)
+	return func() {
+		defaultMu.Lock()
+		defer defaultMu.Unlock()
+		defaultLogger.Store(previous)
+	}
+}
+
+// FromContext 返回附加了 ctx 中追踪ID与字段的默认日志记录器，便于在请求处理函数中使用。
+//
+// 示例：
+//
+//	monophonic.FromContext(c.Request.Context()).Info("user logged in")
+func FromContext(ctx context.Context) *logger.GLogger {
+	return Default().WithContext(ctx)
+}
+
+// Option 是 NewWithOptions 使用的函数式选项，用于修改 logger.Config。
+type Option func(cfg *logger.Config)
+
+// WithLevel 设置所有输出共享的日志级别，各输出可通过自身级别进一步限制。
+func WithLevel(level string) Option {
+	return func(cfg *logger.Config) {
+		cfg.Level = level
+	}
+}
+
+// WithSink 追加一个日志输出目的地。
+func WithSink(sink logger.SinkConfig) Option {
+	return func(cfg *logger.Config) {
+		cfg.Sinks = append(cfg.Sinks, sink)
+	}
+}
+
+// WithStdout 追加标准输出，level 为空时不额外限制。
+func WithStdout(level string, encoding logger.Encoding) Option {
+	return WithSink(logger.SinkConfig{Type: logger.SinkStdout, Level: level, Encoding: encoding})
+}
+
+// WithStderr 追加标准错误输出，level 为空时不额外限制。
+func WithStderr(level string, encoding logger.Encoding) Option {
+	return WithSink(logger.SinkConfig{Type: logger.SinkStderr, Level: level, Encoding: encoding})
+}
+
+// WithFile 追加按 rotate 切割的文件输出，level 为空时不额外限制。
+func WithFile(path string, level string, encoding logger.Encoding, rotate logger.RotateConfig) Option {
+	return WithSink(logger.SinkConfig{Type: logger.SinkFile, Path: path, Level: level, Encoding: encoding, Rotate: &rotate})
+}
+
+// WithWriter 追加自定义的 io.Writer 输出，level 为空时不额外限制。
+func WithWriter(w io.Writer, level string, encoding logger.Encoding) Option {
+	return WithSink(logger.SinkConfig{Type: logger.SinkWriter, Writer: w, Level: level, Encoding: encoding})
+}
+
+// WithOTel 追加通过 OpenTelemetry 日志桥接输出到 provider 的输出，provider 为 nil 时使用全局的 LoggerProvider，
+// level 为空时不额外限制。日志记录会关联到 ctx 中 OpenTelemetry span 的 trace_id 与 span_id。
+func WithOTel(provider log.LoggerProvider, level string) Option {
+	return WithSink(logger.SinkConfig{Type: logger.SinkOTel, LoggerProvider: provider, Level: level})
+}
+
+// WithRedact 设置作用于全部输出的脱敏规则，通常以 logger.DefaultRedactConfig 为基础进行修改。
+func WithRedact(redact logger.RedactConfig) Option {
+	return func(cfg *logger.Config) {
+		cfg.Redact = &redact
+	}
+}
+
+// WithSampling 为全部输出启用采样与限流，通常以 logger.DefaultSamplingConfig 为基础进行修改。
+// 未使用此选项时不采样也不限流。
+func WithSampling(sampling logger.SamplingConfig) Option {
+	return func(cfg *logger.Config) {
+		cfg.Sampling = &sampling
+	}
+}
+
+// WithCallerSkip 设置记录 caller 时额外跳过的栈帧数，用于统一通过包装函数记录日志的场景。
+// 只有部分调用经过包装时，请改用 GLogger.WithCallerSkip 或 logger.Helper。
+func WithCallerSkip(skip int) Option {
+	return func(cfg *logger.Config) {
+		cfg.CallerSkip = skip
+	}
+}
+
+// WithTraceIDGenerator 设置 GenerateTraceId 使用的追踪ID生成器，如 logger.ULIDGenerator()。
+func WithTraceIDGenerator(generator logger.TraceIDGenerator) Option {
+	return func(cfg *logger.Config) {
+		cfg.TraceIDGenerator = generator
+	}
+}
+
+// Start 通过默认日志记录器在 ctx 的追踪中开始一个 span，结束时输出调试级别的日志，见 logger.GLogger.Start。
+//
+// 示例：
+//
+//	ctx, end := monophonic.Start(c.Request.Context(), "load orders")
+//	defer end()
+func Start(ctx context.Context, name string) (context.Context, func(fields ...zapcore.Field)) {
+	return Default().Start(ctx, name)
+}
+
+// WriteChromeTrace 按默认日志记录器的脱敏规则将 spans 导出为 Chrome Trace Event 格式的 JSON，见 logger.GLogger.WriteChromeTrace。
+func WriteChromeTrace(w io.Writer, spans []logger.Span) error {
+	return Default().WriteChromeTrace(w, spans)
+}
+
+// NewWithOptions 按函数式选项创建 GLogger 实例。
+// 未设置级别时默认为 debug，未设置任何输出时默认输出到标准输出；级别或输出的配置不合法时返回错误。
+//
+// 示例：
+//
+//	monophonic.NewWithOptions(
+//		monophonic.WithLevel("debug"),
+//		monophonic.WithStdout("", logger.EncodingConsole),
+//		monophonic.WithFile("tmp/run.log", "info", logger.EncodingJSON, logger.DefaultRotateConfig()),
+//	)
+func NewWithOptions(opts ...Option) (*logger.GLogger, error) {
+	cfg := logger.Config{Level: "debug"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return logger.NewGLogger(cfg)
+}
+
+// NewFromEnv 按 MONOPHONIC_* 环境变量创建 GLogger 实例，变量说明见 logger.ConfigFromEnv。
+func NewFromEnv() (*logger.GLogger, error) {
+	cfg, err := logger.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return logger.NewGLogger(cfg)
+}
+
+// NewFromFile 按 YAML/JSON 配置文件创建 GLogger 实例。
+// 如需在文件变化时自动应用新配置，可配合 logger.WatchConfigFile 使用：
+//
+//	glogger, err := monophonic.NewFromFile("configs/log.yaml")
+//	if err != nil {
+//		panic(err)
+//	}
+//	watcher := logger.WatchConfigFile(glogger, "configs/log.yaml", 5*time.Second)
+//	defer watcher.Stop()
+func NewFromFile(path string) (*logger.GLogger, error) {
+	cfg, err := logger.LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return logger.NewGLogger(cfg)
+}
 
 // New 初始化并返回一个新的 Ginebra 日志实例。
 // 此函数根据配置设置日志级别、路径以及输出目的地（控制台和/或文件）。
@@ -17,32 +210,33 @@ var Default = New("debug", "tmp/run.log")
 // @Description:
 //
 //	初始化日志模块，配置日志级别、输出格式及存储位置。
+//	等价于使用 NewWithOptions 配置控制台与文件两个输出。
 //
+// @Param level string: 日志级别，无法识别时与 GetLogLevel 一致使用 info。
+// @Param opts ...Option: 追加的选项，如 WithSampling、WithRedact，在默认输出之后应用，不合法时 panic。
 // @Return *GLogger: 返回配置好的 GLogger 实例，可用于日志记录。
-// TODO: 考虑后期日志输出级别从环境变量中获取，以及动态配置日志级别
-func New(level string, logfile string) *logger.GLogger {
-	// 从配置中获取日志级别和路径信息
-	logLevel := logger.GetLogLevel(level)
-	logPath := logfile
-
-	// 配置日志编码器，用于格式化输出到控制台的日志
-	encoder := logger.GetEncoder()
-
-	// 准备文件写入器，用于将日志记录到指定文件
-	fileWriteSyncer := logger.GetFileLogWriter(logPath)
-
-	// 设置日志核心，允许同时输出到控制台和文件，根据环境调整此逻辑
-	core := zapcore.NewTee(
+// 如需从环境变量或配置文件读取配置，请使用 NewFromEnv 或 NewFromFile。
+func New(level string, logfile string, opts ...Option) *logger.GLogger {
+	if !logger.ValidLevel(level) {
+		// 与 GetLogLevel 一致，无法识别的级别使用 info，避免配置中的拼写错误导致启动失败
+		level = zapcore.InfoLevel.String()
+	}
+	extra := opts
+	opts = []Option{
+		WithLevel(level),
 		// 注意：生产环境中应考虑移除或调整控制台输出
-		zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), logLevel),
-		zapcore.NewCore(encoder, fileWriteSyncer, logLevel),
-	)
+		WithStdout("", logger.EncodingConsole),
+	}
+	if logfile != "" {
+		// 文件输出使用 JSON 格式，便于日志采集系统解析
+		opts = append(opts, WithFile(logfile, "", logger.EncodingJSON, logger.DefaultRotateConfig()))
+	}
+	opts = append(opts, extra...)
 
-	// 创建并返回 GLogger 实例，其中包含日志级别信息及 zap.Logger 的封装
-	// 添加 zap.AddCaller 和 zap.AddCallerSkip 以便在日志中记录调用者信息
-	return &logger.GLogger{
-		ZapLogger: zap.New(core, zap.AddCaller(), zap.AddCallerSkip(2)),
-		LogLevel:  level,
-		LogPath:   logPath,
+	glogger, err := NewWithOptions(opts...)
+	if err != nil {
+		// 级别已经检查过，默认的输出配置是固定且合法的，只有追加的选项不合法时才会出现错误
+		panic(err)
 	}
+	return glogger
 }
@@ -2,6 +2,7 @@ package response
 
 import (
 	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,18 +21,19 @@ var DefaultReturn = &Response{}
 // @param err error: 错误对象，用于获取错误信息。
 // @param msg string: 自定义错误消息。
 func Error(c *gin.Context, code int, err error, msg string) {
+	logger.Helper() // 日志的 caller 指向调用 Error 的处理函数
 	// 克隆默认响应对象以复用
 	res := DefaultReturn.Clone()
-	res.Success(false)                                // 标记响应为失败
-	res.SetTraceID(monophonic.Default.GenerateTraceId()) // 设置追踪ID
-	res.SetCode(int32(code))                          // 设置错误代码
-	res.SetMsg(msg)                                   // 设置错误消息
-	res.SetInfo(msg)                                  // 设置附加信息（与msg相同，可根据实际情况调整）
-	if err != nil {                                   // 如果有具体的错误对象，则设置错误信息
+	res.Success(false)           // 标记响应为失败
+	res.SetTraceID(RequestID(c)) // 设置追踪ID
+	res.SetCode(int32(code))     // 设置错误代码
+	res.SetMsg(msg)              // 设置错误消息
+	res.SetInfo(msg)             // 设置附加信息（与msg相同，可根据实际情况调整）
+	if err != nil {              // 如果有具体的错误对象，则设置错误信息
 		res.SetInfo(err.Error())
 	}
 	// 记录错误日志
-	monophonic.Default.Error(TagReturn+c.FullPath(), res.GetFields()...)
+	monophonic.Default().ErrorCtx(c.Request.Context(), TagReturn+c.FullPath(), res.GetFields()...)
 	// 将响应对象放入上下文中
 	c.Set("result", res)
 	// 向客户端发送错误响应并终止后续中间件处理
@@ -44,18 +46,50 @@ func Error(c *gin.Context, code int, err error, msg string) {
 // @param data any: 成功响应携带的数据。
 // @param msg string: 成功消息。
 func OK(c *gin.Context, data any, msg string) {
+	logger.Helper() // 日志的 caller 指向调用 OK 的处理函数
 	// 克隆默认响应对象
 	res := DefaultReturn.Clone()
-	res.Success(true)                                 // 标记响应为成功
-	res.SetTraceID(monophonic.Default.GenerateTraceId()) // 设置追踪ID
-	res.SetCode(http.StatusOK)                        // 设置状态码为200
-	res.SetMsg(msg)                                   // 设置成功消息
-	res.SetInfo(msg)                                  // 设置附加信息（与msg相同，可根据实际情况调整）
-	res.SetData(data)                                 // 设置响应数据
+	res.Success(true)            // 标记响应为成功
+	res.SetTraceID(RequestID(c)) // 设置追踪ID
+	res.SetCode(http.StatusOK)   // 设置状态码为200
+	res.SetMsg(msg)              // 设置成功消息
+	res.SetInfo(msg)             // 设置附加信息（与msg相同，可根据实际情况调整）
+	res.SetData(data)            // 设置响应数据
 	// 记录成功日志
-	monophonic.Default.Info(TagReturn+c.FullPath(), res.GetFields()...)
+	monophonic.Default().InfoCtx(c.Request.Context(), TagReturn+c.FullPath(), res.GetFields()...)
 	// 将响应对象放入上下文中
 	c.Set("result", res)
 	// 向客户端发送成功响应并终止后续中间件处理
 	c.AbortWithStatusJSON(http.StatusOK, res)
 }
+
+// RequestIDKey 是请求ID在 gin.Context 中的键，由 middleware.RequestID 或 GinLogger 写入。
+const RequestIDKey = "requestId"
+
+// RequestID 返回当前请求的请求ID，响应中的 requestId 与该请求的日志使用同一个ID。
+// 依次读取 gin.Context 与请求的 context.Context，均不存在时（如未注册 middleware.RequestID 与 GinLogger）
+// 生成一个新的ID并写入两者，使同一请求中之后的响应与日志沿用该ID。
+// @param c *gin.Context: Gin框架的上下文。
+// @return string: 请求ID。
+func RequestID(c *gin.Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+	id := logger.TraceIDFromContext(c.Request.Context())
+	if id == "" {
+		id = monophonic.Default().GenerateTraceId()
+	}
+	SetRequestID(c, id)
+	return id
+}
+
+// SetRequestID 将请求ID写入 gin.Context 与请求的 context.Context，
+// 之后通过 Ctx 系列方法记录的日志都会带上该ID。
+// @param c *gin.Context: Gin框架的上下文。
+// @param id string: 请求ID。
+func SetRequestID(c *gin.Context, id string) {
+	c.Set(RequestIDKey, id)
+	if logger.TraceIDFromContext(c.Request.Context()) != id {
+		c.Request = c.Request.WithContext(logger.WithTraceID(c.Request.Context(), id))
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+	"go.uber.org/zap"
+)
+
+func TestMonophonicRedirectStdLog(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithLevel("debug"))
+	t.Cleanup(monophonic.SetDefault(glogger))
+	output, flags := log.Writer(), log.Flags()
+	restore := monophonic.RedirectStdLog("warn")
+	log.Printf("legacy %d", 1)
+	log.Print("line one\nline two")
+	restore()
+	if log.Writer() != output || log.Flags() != flags {
+		t.Error("restore should bring back the previous output and flags")
+	}
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0]["msg"] != "legacy 1" || entries[0]["level"] != "warn" || entries[2]["msg"] != "line two" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+}
+
+func TestMonophonicRedirectGinWriters(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithLevel("gin=info,*=debug"))
+	t.Cleanup(monophonic.SetDefault(glogger))
+	restore := middleware.RedirectGinWriters("debug", "error")
+	defer restore()
+
+	mode := gin.Mode()
+	gin.SetMode(gin.DebugMode)
+	defer gin.SetMode(mode)
+
+	engine := gin.New()
+	engine.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	_, _ = gin.DefaultErrorWriter.Write([]byte("[GIN-debug] [ERROR] listen failed\n"))
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("debug output should follow the gin module level, got %d entries", len(entries))
+	}
+	if entries[0]["logger"] != middleware.GinDebugLoggerName || entries[0]["level"] != "error" {
+		t.Errorf("unexpected entry: %v", entries[0])
+	}
+
+	buf.Reset()
+	monophonic.Default().Named(middleware.GinDebugLoggerName).SetLogLevel("debug")
+	engine.GET("/pong", func(c *gin.Context) { c.Status(http.StatusOK) })
+	entries = decodeLines(t, buf)
+	if len(entries) != 1 || !strings.Contains(entries[0]["msg"].(string), "/pong") || entries[0]["level"] != "debug" {
+		t.Errorf("route registration should be logged at debug: %v", entries)
+	}
+}
+
+func TestMonophonicLogrSink(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(monophonic.WithLevel("info"), monophonic.WithWriter(&buf, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := logr.New(logger.NewLogrSink(glogger)).WithName("controller").WithValues("kind", "Pod")
+	l.V(1).Info("debug detail")
+	l.Info("reconciled", "name", "web", zap.Int("replicas", 3), "dangling")
+	l.Error(errors.New("boom"), "reconcile failed")
+	if l.V(1).Enabled() || !l.V(0).Enabled() {
+		t.Error("V(1) should map to debug and V(0) to info")
+	}
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	info := entries[0]
+	if info["logger"] != "controller" || info["kind"] != "Pod" || info["name"] != "web" || info["replicas"] != float64(3) || info["dangling"] != "(MISSING)" {
+		t.Errorf("unexpected info entry: %v", info)
+	}
+	if entries[1]["level"] != "error" || entries[1]["error"] != "boom" {
+		t.Errorf("unexpected error entry: %v", entries[1])
+	}
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+)
+
+// gateWriter 在 release 之前阻塞所有写入，用于模拟缓慢的输出。
+type gateWriter struct {
+	entered chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func newAsyncLogger(t *testing.T, w *gateWriter, async logger.AsyncConfig) *logger.GLogger {
+	t.Helper()
+	glogger, err := monophonic.NewWithOptions(monophonic.WithSink(logger.SinkConfig{
+		Type:     logger.SinkWriter,
+		Writer:   w,
+		Encoding: logger.EncodingJSON,
+		Async:    &async,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return glogger
+}
+
+func TestMonophonicAsyncSinkFlushesOnSync(t *testing.T) {
+	w := newGateWriter()
+	close(w.gate)
+	glogger := newAsyncLogger(t, w, logger.AsyncConfig{QueueSize: 16, BatchSize: 4, FlushInterval: time.Hour})
+
+	for i := 0; i < 10; i++ {
+		glogger.Info(fmt.Sprintf("entry-%d", i))
+	}
+	if err := glogger.ZapLogger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := w.String()
+	last := -1
+	for i := 0; i < 10; i++ {
+		idx := strings.Index(out, fmt.Sprintf("entry-%d\"", i))
+		if idx <= last {
+			t.Fatalf("entry-%d missing or out of order: %q", i, out)
+		}
+		last = idx
+	}
+}
+
+func TestMonophonicAsyncSinkDropNewest(t *testing.T) {
+	w := newGateWriter()
+	glogger := newAsyncLogger(t, w, logger.AsyncConfig{QueueSize: 4, BatchSize: 1, FlushInterval: time.Hour, Overflow: logger.OverflowDropNewest})
+
+	glogger.Info("in-flight")
+	<-w.entered
+	for i := 0; i < 7; i++ {
+		glogger.Info(fmt.Sprintf("queued-%d", i))
+	}
+	if got := glogger.Dropped(); got != 3 {
+		t.Errorf("Dropped() = %d, want 3", got)
+	}
+
+	close(w.gate)
+	if err := glogger.ZapLogger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	out := w.String()
+	if !strings.Contains(out, "queued-3") || strings.Contains(out, "queued-4") {
+		t.Errorf("unexpected entries after dropping: %q", out)
+	}
+	if !strings.Contains(out, `"dropped":3`) {
+		t.Errorf("drop count was not reported: %q", out)
+	}
+}
+
+func TestMonophonicAsyncSinkDropBelowLevel(t *testing.T) {
+	w := newGateWriter()
+	glogger := newAsyncLogger(t, w, logger.AsyncConfig{QueueSize: 2, BatchSize: 1, FlushInterval: time.Hour, Overflow: logger.OverflowDropBelowLevel})
+
+	glogger.Info("in-flight")
+	<-w.entered
+	glogger.Info("queued-1")
+	glogger.Info("queued-2")
+	glogger.Info("dropped info")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		glogger.Error("kept error")
+	}()
+	close(w.gate)
+	<-done
+	if err := glogger.ZapLogger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := w.String()
+	if strings.Contains(out, "dropped info") || !strings.Contains(out, "kept error") {
+		t.Errorf("only entries below the drop level should be dropped: %q", out)
+	}
+}
+
+func TestMonophonicAsyncFileConfig(t *testing.T) {
+	cfg, err := logger.ParseConfig([]byte("outputs:\n  - type: stdout\n    async:\n      queue_size: 8\n      flush_interval: 250ms\n      overflow: drop_oldest\n"), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	async := cfg.Sinks[0].Async
+	if async == nil || async.QueueSize != 8 || async.FlushInterval != 250*time.Millisecond || async.Overflow != logger.OverflowDropOldest {
+		t.Errorf("unexpected async config: %+v", async)
+	}
+
+	if _, err := logger.ParseConfig([]byte("outputs:\n  - type: stdout\n    async:\n      overflow: sometimes\n"), "yaml"); err == nil {
+		t.Error("expected an error for an unknown overflow policy")
+	}
+}
+
+func TestMonophonicAsyncSinkCloseKeepsOrder(t *testing.T) {
+	// 在写入的同时关闭，每个写入方的日志都必须按写入顺序输出，阻塞的写入方排在队列中的日志之后
+	for round := 0; round < 50; round++ {
+		w := newGateWriter()
+		close(w.gate)
+		glogger := newAsyncLogger(t, w, logger.AsyncConfig{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour})
+
+		const writers, n = 4, 50
+		var wg sync.WaitGroup
+		for g := 0; g < writers; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					glogger.Info(fmt.Sprintf("entry-%d-%d", g, i))
+				}
+			}(g)
+		}
+		for !strings.Contains(w.String(), "entry-") {
+			runtime.Gosched()
+		}
+		if err := glogger.Close(); err != nil {
+			t.Fatal(err)
+		}
+		wg.Wait()
+
+		out := w.String()
+		for g := 0; g < writers; g++ {
+			last := -1
+			for i := 0; i < n; i++ {
+				idx := strings.Index(out, fmt.Sprintf("entry-%d-%d\"", g, i))
+				if idx <= last {
+					t.Fatalf("round %d: entry-%d-%d missing or out of order", round, g, i)
+				}
+				last = idx
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+	"github.com/uniharmonic/monophonic/response"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// line 返回调用处的下一行行号，用于断言紧随其后的一行日志的 caller。
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l + 1
+}
+
+// assertCaller 断言 entry 的 caller 为本文件的第 want 行。
+func assertCaller(t *testing.T, entry map[string]any, want int) {
+	t.Helper()
+	caller, _ := entry["caller"].(string)
+	if !strings.HasSuffix(caller, fmt.Sprintf("/test/caller_test.go:%d", want)) {
+		t.Errorf("%v: caller = %q, want caller_test.go:%d", entry["msg"], caller, want)
+	}
+}
+
+func TestMonophonicCallerDirect(t *testing.T) {
+	glogger, buf := newBufferLogger(t)
+	ctx := logger.WithTraceID(context.Background(), "trace")
+
+	var want []int
+	want = append(want, line())
+	glogger.Info("info")
+	want = append(want, line())
+	glogger.WarnCtx(ctx, "ctx")
+	want = append(want, line())
+	glogger.Infof("%s", "sugared")
+	want = append(want, line())
+	glogger.Named("child").With().Errorw("child")
+	want = append(want, line())
+	logr.New(logger.NewLogrSink(glogger)).Info("logr")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, entry := range entries {
+		assertCaller(t, entry, want[i])
+	}
+}
+
+// logVia 固定包装了一层日志记录，通过 WithCallerSkip 跳过自身。
+func logVia(glogger *logger.GLogger, msg string) {
+	glogger.WithCallerSkip(1).Info(msg)
+}
+
+// logViaHelper 通过 logger.Helper 标记自身为辅助函数。
+func logViaHelper(glogger *logger.GLogger, msg string) {
+	logger.Helper()
+	glogger.Warnf("%s", msg)
+}
+
+// logViaNestedHelper 调用另一个辅助函数，caller 仍应指向最外层的调用方。
+func logViaNestedHelper(glogger *logger.GLogger, msg string) {
+	logger.Helper()
+	logViaHelper(glogger, msg)
+}
+
+func TestMonophonicCallerWrappers(t *testing.T) {
+	glogger, buf := newBufferLogger(t)
+
+	var want []int
+	want = append(want, line())
+	logVia(glogger, "skip")
+	want = append(want, line())
+	logViaHelper(glogger, "helper")
+	want = append(want, line())
+	logViaNestedHelper(glogger, "nested")
+
+	entries := decodeLines(t, buf)
+	for i, entry := range entries {
+		assertCaller(t, entry, want[i])
+	}
+}
+
+func TestMonophonicCallerHelpersAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not stable under the race detector")
+	}
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(io.Discard, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 登记辅助函数后，不经过辅助函数的调用不应再因检查调用栈而额外分配内存
+	logViaHelper(glogger, "warm up")
+	glogger.Info("warm up")
+	want := testing.AllocsPerRun(100, func() { glogger.ZapLogger.Info("plain") })
+	if allocs := testing.AllocsPerRun(100, func() { glogger.Info("plain") }); allocs != want {
+		t.Errorf("plain calls should allocate as much as zap (%v), got %v", want, allocs)
+	}
+}
+
+// logThroughFacade 模拟统一通过包装函数记录日志的场景。
+func logThroughFacade(glogger *logger.GLogger, msg string) {
+	glogger.Info(msg)
+}
+
+func TestMonophonicCallerSkipOption(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithCallerSkip(1))
+	want := line()
+	logThroughFacade(glogger, "facade")
+	assertCaller(t, decodeLines(t, buf)[0], want)
+}
+
+func TestMonophonicCallerMiddleware(t *testing.T) {
+	glogger, buf := newBufferLogger(t)
+	t.Cleanup(monophonic.SetDefault(glogger))
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), middleware.GetGormConfig("debug"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []int
+	engine := gin.New()
+	engine.Use(middleware.GinLogger())
+	engine.GET("/ping", func(c *gin.Context) {
+		want = append(want, line())
+		db.WithContext(c.Request.Context()).Exec("SELECT 1")
+		want = append(want, line())
+		response.OK(c, nil, "pong")
+	})
+	buf.Reset()
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected gorm, response and access entries, got %d", len(entries))
+	}
+	assertCaller(t, entries[0], want[0])
+	assertCaller(t, entries[1], want[1])
+	if caller, _ := entries[2]["caller"].(string); !strings.Contains(caller, "/middleware/gin_logger.go:") {
+		t.Errorf("access log caller = %q, want the GinLogger middleware", caller)
+	}
+}
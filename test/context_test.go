@@ -0,0 +1,108 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+	"github.com/uniharmonic/monophonic/response"
+	"go.uber.org/zap"
+)
+
+// decodeLines 将 JSON 编码的日志逐行解析。
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid json log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// newBufferLogger 创建在 opts 之后追加了 JSON 格式内存输出的日志记录器，返回日志记录器及其输出。
+func newBufferLogger(t *testing.T, opts ...monophonic.Option) (*logger.GLogger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	opts = append(opts, monophonic.WithWriter(&buf, "", logger.EncodingJSON))
+	glogger, err := monophonic.NewWithOptions(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return glogger, &buf
+}
+
+func TestMonophonicContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(&buf, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := logger.WithTraceID(context.Background(), "trace-1")
+	ctx = logger.WithFields(ctx, zap.String("user", "alice"))
+	glogger.InfoCtx(ctx, "ctx message", zap.Int("n", 1))
+	glogger.WithContext(ctx).Warn("scoped message")
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry[logger.TraceIDKey] != "trace-1" || entry["user"] != "alice" {
+			t.Errorf("context fields missing: %v", entry)
+		}
+	}
+	if logger.TraceIDFromContext(context.Background()) != "" {
+		t.Error("empty context should not carry a trace id")
+	}
+}
+
+func TestMonophonicRequestSharesTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(&buf, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(monophonic.SetDefault(glogger))
+
+	engine := gin.New()
+	engine.Use(middleware.GinLogger())
+	engine.GET("/ping", func(c *gin.Context) {
+		(&middleware.GormLogger{}).Trace(c.Request.Context(), time.Now(), func() (string, int64) {
+			return "SELECT 1", 1
+		}, nil)
+		response.OK(c, nil, "pong")
+	})
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	var body response.Response
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	entries := decodeLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected gorm, response and access entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry[logger.TraceIDKey] != body.TraceID || body.TraceID == "" {
+			t.Errorf("entry %v does not share the response trace id %q", entry, body.TraceID)
+		}
+	}
+}
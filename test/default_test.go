@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+)
+
+func TestMonophonicDefaultIsLazy(t *testing.T) {
+	t.Setenv(logger.EnvLevel, "warn")
+	restore := monophonic.SetDefault(nil)
+	defer restore()
+
+	glogger := monophonic.Default()
+	if got := glogger.GetLogLevel(); got != "warn" {
+		t.Errorf("Default() level = %q, want warn from the environment", got)
+	}
+	if glogger.LogPath != "" {
+		t.Errorf("Default() should not write to a file, got %q", glogger.LogPath)
+	}
+	if monophonic.Default() != glogger {
+		t.Error("Default() should return the same instance once initialised")
+	}
+}
+
+func TestMonophonicSetDefaultConcurrent(t *testing.T) {
+	restore := monophonic.SetDefault(nil)
+	defer restore()
+
+	replacement, err := monophonic.NewWithOptions(monophonic.WithLevel("error"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			monophonic.Default().Debug("concurrent access")
+		}()
+		go func() {
+			defer wg.Done()
+			monophonic.SetDefault(replacement)
+		}()
+	}
+	wg.Wait()
+
+	if monophonic.Default() != replacement {
+		t.Error("SetDefault did not replace the default logger")
+	}
+}
+
+func TestMonophonicGormLoggerFollowsDefault(t *testing.T) {
+	gormLogger := middleware.NewGormLogger(0)
+
+	first, firstBuf := newBufferLogger(t, monophonic.WithLevel("info"))
+	t.Cleanup(monophonic.SetDefault(first))
+	gormLogger.Info(context.Background(), "first")
+	gormLogger.Info(context.Background(), "first again")
+
+	second, secondBuf := newBufferLogger(t, monophonic.WithLevel("info"))
+	restore := monophonic.SetDefault(second)
+	defer restore()
+	gormLogger.Info(context.Background(), "second")
+
+	if got := decodeLines(t, firstBuf); len(got) != 2 || got[0]["logger"] != middleware.GormLoggerName {
+		t.Errorf("first default should get 2 gorm entries, got %v", got)
+	}
+	if got := decodeLines(t, secondBuf); len(got) != 1 || got[0]["logger"] != middleware.GormLoggerName {
+		t.Errorf("replaced default should get 1 gorm entry, got %v", got)
+	}
+}
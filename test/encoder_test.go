@@ -0,0 +1,76 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.uber.org/zap"
+)
+
+func TestMonophonicLogfmtEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(&buf, "", logger.EncodingLogfmt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glogger.Named("http").Info("request done",
+		zap.Int("status", 200),
+		zap.String("path", "/users list"),
+		zap.Any("user", map[string]any{"id": 7}),
+		zap.Strings("tags", []string{"a", "b"}),
+	)
+
+	line := strings.TrimSpace(buf.String())
+	for _, want := range []string{
+		"level=info", "logger=http", `msg="request done"`, "status=200",
+		`path="/users list"`, "user.id=7", `tags="[\"a\",\"b\"]"`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("logfmt line is missing %q: %q", want, line)
+		}
+	}
+	if strings.Index(line, "status=") > strings.Index(line, "path=") {
+		t.Errorf("logfmt should keep the field order: %q", line)
+	}
+}
+
+func TestMonophonicConsoleEncoderWithoutTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(&buf, "", logger.EncodingConsole))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glogger.Warn("plain text")
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("console output to a non-terminal writer should not contain colors: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "WARN") {
+		t.Errorf("console output should still contain the level: %q", buf.String())
+	}
+}
+
+func TestMonophonicFileSinkDefaultsToJSON(t *testing.T) {
+	logfile := filepath.Join(t.TempDir(), "run.log")
+	glogger := monophonic.New("info", logfile)
+	glogger.Info("file entry")
+
+	data, err := os.ReadFile(logfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(data), &entry); err != nil {
+		t.Fatalf("file sink should default to json, got %q: %v", data, err)
+	}
+	if entry["msg"] != "file entry" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
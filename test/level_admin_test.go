@@ -0,0 +1,168 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+)
+
+// syncBuffer 是可以并发写入的 bytes.Buffer，用于接收到期恢复时由定时器 goroutine 输出的日志。
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newLevelAdminEngine(t *testing.T) (*gin.Engine, *logger.GLogger, *syncBuffer) {
+	audit := &syncBuffer{}
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(audit, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(monophonic.SetDefault(glogger))
+
+	db, err := monophonic.NewWithOptions(monophonic.WithLevel("warn"), monophonic.WithWriter(&bytes.Buffer{}, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := middleware.NewLevelAdmin()
+	admin.Register("db", db)
+
+	engine := gin.New()
+	admin.Routes(engine.Group("/admin/log/level"))
+	return engine, db, audit
+}
+
+func TestMonophonicLevelAdmin(t *testing.T) {
+	engine, db, audit := newLevelAdminEngine(t)
+
+	body := strings.NewReader(`{"logger":"db","level":"debug"}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/log/level", body)
+	req.Header.Set(middleware.OperatorHeader, "alice")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := db.GetLogLevel(); got != "debug" {
+		t.Errorf("db level = %q, want debug", got)
+	}
+	if !strings.Contains(audit.String(), `"operator":"alice"`) {
+		t.Errorf("level change was not audited: %q", audit.String())
+	}
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/admin/log/level", nil))
+	var result struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Data["db"] != "debug" || result.Data[middleware.DefaultLoggerName] != "debug" {
+		t.Errorf("unexpected levels: %v", result.Data)
+	}
+}
+
+func TestMonophonicLevelAdminTTL(t *testing.T) {
+	engine, db, _ := newLevelAdminEngine(t)
+
+	body := strings.NewReader(`{"logger":"db","level":"debug","ttl":"50ms"}`)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/admin/log/level", body))
+	if got := db.GetLogLevel(); got != "debug" {
+		t.Fatalf("db level = %q, want debug", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for db.GetLogLevel() != "warn" {
+		if time.Now().After(deadline) {
+			t.Fatalf("db level was not reverted, got %q", db.GetLogLevel())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMonophonicLevelAdminRejectsInvalid(t *testing.T) {
+	engine, db, _ := newLevelAdminEngine(t)
+
+	for _, body := range []string{
+		`{"logger":"db","level":"verbose"}`,
+		`{"logger":"db","level":"debug","ttl":"soon"}`,
+		`{"logger":"cache","level":"debug"}`,
+	} {
+		res := httptest.NewRecorder()
+		engine.ServeHTTP(res, httptest.NewRequest(http.MethodPut, "/admin/log/level", strings.NewReader(body)))
+		if !strings.Contains(res.Body.String(), `"status":"error"`) {
+			t.Errorf("%s: expected an error response, got %q", body, res.Body.String())
+		}
+	}
+	if got := db.GetLogLevel(); got != "warn" {
+		t.Errorf("db level = %q, want warn", got)
+	}
+}
+
+func TestMonophonicLevelAdminBuiltinNames(t *testing.T) {
+	engine, _, _ := newLevelAdminEngine(t)
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/admin/log/level", nil))
+	var result struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{middleware.HTTPLoggerName, middleware.HTTPClientLoggerName, middleware.GormLoggerName} {
+		if result.Data[name] != "debug" {
+			t.Errorf("built-in logger %q missing from %v", name, result.Data)
+		}
+	}
+
+	body := strings.NewReader(`{"logger":"gorm","level":"warn"}`)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/admin/log/level", body))
+	if got := monophonic.Default().Named(middleware.GormLoggerName).GetLogLevel(); got != "warn" {
+		t.Errorf("gorm level = %q, want warn", got)
+	}
+}
+
+func TestMonophonicLevelAdminTTLRestoresInheritance(t *testing.T) {
+	engine, _, _ := newLevelAdminEngine(t)
+	gorm := monophonic.Default().Named(middleware.GormLoggerName)
+
+	body := strings.NewReader(`{"logger":"gorm","level":"warn","ttl":"50ms"}`)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/admin/log/level", body))
+	if got := gorm.GetLogLevel(); got != "warn" {
+		t.Fatalf("gorm level = %q, want warn", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for gorm.GetLogLevel() != "debug" {
+		if time.Now().After(deadline) {
+			t.Fatalf("gorm level was not reverted, got %q", gorm.GetLogLevel())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 恢复后应继续沿用全局级别，而不是固定为修改前的 debug
+	monophonic.Default().SetLogLevel("error")
+	if got := gorm.GetLogLevel(); got != "error" {
+		t.Errorf("gorm level = %q after changing the root level, want error", got)
+	}
+}
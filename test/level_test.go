@@ -0,0 +1,120 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMonophonicSetLogLevelKeepsChildren(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("info"),
+		monophonic.WithWriter(&buf, "", logger.EncodingJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := glogger.ZapLogger.With(zap.String("module", "child"))
+
+	child.Debug("hidden")
+	glogger.SetLogLevel("debug")
+	if got := glogger.GetLogLevel(); got != "debug" {
+		t.Errorf("GetLogLevel() = %q, want debug", got)
+	}
+	child.Debug("visible")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("debug entry logged before level change: %q", out)
+	}
+	if !strings.Contains(out, "visible") || !strings.Contains(out, `"module":"child"`) {
+		t.Errorf("child logger lost level change or fields: %q", out)
+	}
+}
+
+func TestMonophonicSetLogLevelConcurrent(t *testing.T) {
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(&bytes.Buffer{}, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, level := range []string{"debug", "info", "warn", "error"} {
+		wg.Add(1)
+		go func(level string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				glogger.SetLogLevel(level)
+				_ = glogger.GetLogLevel()
+			}
+		}(level)
+	}
+	wg.Wait()
+}
+
+func TestMonophonicSetLogLevelInvalidKeepsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("warn"),
+		monophonic.WithWriter(&buf, "", logger.EncodingJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glogger.SetLogLevel("gorm=verbose")
+	if got := glogger.GetLogLevel(); got != "warn" {
+		t.Errorf("GetLogLevel() = %q, want warn", got)
+	}
+	if glogger.LogLevel != "warn" {
+		t.Errorf("LogLevel = %q, want warn", glogger.LogLevel)
+	}
+	glogger.Info("hidden")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("info entry logged after invalid level: %q", out)
+	}
+	if !strings.Contains(out, "invalid level") || !strings.Contains(out, "gorm=verbose") {
+		t.Errorf("invalid level not reported: %q", out)
+	}
+}
+
+func TestMonophonicSetLogLevelCompatConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.Lock(zapcore.AddSync(&buf)), zapcore.DebugLevel)
+	glogger := &logger.GLogger{ZapLogger: zap.New(core)}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			glogger.SetLogLevel("warn")
+			glogger.SetTraceIDGenerator(nil)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			glogger.Info("message")
+			_ = glogger.GenerateTraceId()
+			_ = glogger.GetLogLevel()
+		}
+	}()
+	wg.Wait()
+
+	buf.Reset()
+	glogger.Info("hidden")
+	glogger.With(zap.String("module", "child")).Info("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("info entry logged after SetLogLevel(warn): %q", buf.String())
+	}
+}
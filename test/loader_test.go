@@ -0,0 +1,191 @@
+package test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.uber.org/zap"
+)
+
+func TestMonophonicLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	logfile := filepath.Join(dir, "run.log")
+	yamlPath := filepath.Join(dir, "log.yaml")
+	yamlConfig := "level: warn\noutputs:\n  - type: file\n    path: " + logfile + "\n    format: json\n    rotation:\n      max_size: 10\n"
+	if err := os.WriteFile(yamlPath, []byte(yamlConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := logger.LoadConfigFile(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "warn" || len(cfg.Sinks) != 1 || cfg.Sinks[0].Rotate.MaxSize != 10 || cfg.Sinks[0].Rotate.MaxBackups != 60 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	jsonPath := filepath.Join(dir, "log.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"level":"info","outputs":[{"type":"stdout","format":"json"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := monophonic.NewFromFile(jsonPath); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMonophonicLoadInvalidConfig(t *testing.T) {
+	for _, tc := range []struct{ config, format, want string }{
+		{"level: loud\n", "yaml", "unknown level"},
+		{"levle: info\n", "yaml", "levle"},
+		{"outputs:\n  - type: file\n", "yaml", "requires a path"},
+		{"outputs:\n  - type: stdout\n    format: xml\n", "yaml", "unknown encoding"},
+		{`{"outputs":[{"type":"writer"}]}`, "json", "writer outputs"},
+		{"level = info", "toml", "unknown config format"},
+	} {
+		_, err := logger.ParseConfig([]byte(tc.config), tc.format)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: expected error containing %q, got %v", tc.config, tc.want, err)
+		}
+	}
+}
+
+func TestMonophonicConfigFromEnv(t *testing.T) {
+	logfile := filepath.Join(t.TempDir(), "env.log")
+	t.Setenv(logger.EnvLevel, "error")
+	t.Setenv(logger.EnvOutputs, "stderr,file")
+	t.Setenv(logger.EnvFormat, "json")
+	t.Setenv(logger.EnvFile, logfile)
+	t.Setenv(logger.EnvRotateMaxAge, "7")
+
+	cfg, err := logger.ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "error" || len(cfg.Sinks) != 2 || cfg.Sinks[1].Path != logfile || cfg.Sinks[1].Rotate.MaxAge != 7 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv(logger.EnvRotateMaxAge, "a week")
+	if _, err := logger.ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), logger.EnvRotateMaxAge) {
+		t.Errorf("expected an error naming %s, got %v", logger.EnvRotateMaxAge, err)
+	}
+}
+
+func TestMonophonicWatchConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	configPath := filepath.Join(dir, "log.yaml")
+	writeConfig := func(config string) {
+		t.Helper()
+		if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor := func(cond func() bool, msg string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatal(msg)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	readFile := func(path string) string {
+		data, _ := os.ReadFile(path)
+		return string(data)
+	}
+
+	writeConfig("level: info\noutputs:\n  - type: file\n    path: " + first + "\n    format: json\n")
+	glogger, err := monophonic.NewFromFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := glogger.ZapLogger.With(zap.String("module", "child"))
+	watcher := logger.WatchConfigFile(glogger, configPath, 10*time.Millisecond)
+	defer watcher.Stop()
+
+	writeConfig("level: debug\noutputs:\n  - type: file\n    path: " + second + "\n    format: json\n")
+	waitFor(func() bool { return glogger.GetLogLevel() == "debug" }, "config was not reloaded")
+	child.Debug("after reload")
+	if out := readFile(second); !strings.Contains(out, "after reload") || !strings.Contains(out, `"module":"child"`) {
+		t.Errorf("child logger did not follow the reloaded outputs: %q", out)
+	}
+
+	writeConfig("level: loud\n")
+	waitFor(func() bool { return strings.Contains(readFile(second), "invalid config") }, "invalid config was not reported")
+	if got := glogger.GetLogLevel(); got != "debug" {
+		t.Errorf("invalid config replaced the level, got %q", got)
+	}
+}
+
+func TestMonophonicWatchConfigFileKeepsEnvLevel(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "log.yaml")
+	second := filepath.Join(dir, "second.log")
+	writeConfig := func(level, path string) {
+		t.Helper()
+		config := "level: " + level + "\noutputs:\n  - type: file\n    path: " + path + "\n"
+		if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("info", filepath.Join(dir, "first.log"))
+	t.Setenv(logger.EnvConfig, configPath)
+	t.Setenv(logger.EnvLevel, "warn")
+	glogger, err := monophonic.NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer glogger.Close()
+	watcher := logger.WatchConfigFile(glogger, configPath, 10*time.Millisecond)
+	defer watcher.Stop()
+
+	writeConfig("debug", second)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		glogger.Warn("probe")
+		if data, _ := os.ReadFile(second); strings.Contains(string(data), "probe") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("config was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := glogger.GetLogLevel(); got != "warn" {
+		t.Errorf("%s should still override the file level after a reload, got %q", logger.EnvLevel, got)
+	}
+}
+
+func TestMonophonicReloadRetiresFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	glogger, err := monophonic.NewWithOptions(monophonic.WithFile(first, "", logger.EncodingJSON, logger.DefaultRotateConfig()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer glogger.Close()
+
+	glogger.Info("before reload")
+	// 模拟重新加载时仍持有旧核心的写入
+	late := glogger.ZapLogger.Check(zap.InfoLevel, "late write")
+	err = glogger.Reload(logger.Config{Level: "debug", Sinks: []logger.SinkConfig{{Type: logger.SinkWriter, Writer: io.Discard}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(first); err != nil {
+		t.Fatal(err)
+	}
+	late.Write()
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("a replaced file should not be reopened by late writes: %v", err)
+	}
+}
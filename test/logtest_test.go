@@ -0,0 +1,101 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic/logtest"
+	"github.com/uniharmonic/monophonic/middleware"
+	"github.com/uniharmonic/monophonic/response"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// failureRecorder 记录断言失败的信息，而不是让当前测试失败。
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *failureRecorder) Helper() {}
+
+func (r *failureRecorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestLogtestFilters(t *testing.T) {
+	glogger, logs := logtest.New()
+	glogger.Debug("starting")
+	glogger.Named("orders").Warn("slow order", zap.Int("order", 42))
+	glogger.With(zap.String("user", "alice")).Errorf("payment %s", "failed")
+
+	if got := logs.Messages(); len(got) != 3 || got[0] != "starting" {
+		t.Fatalf("unexpected messages: %v", got)
+	}
+	logs.FilterLevel("WARN").AssertCount(t, 1)
+	logs.FilterLogger("orders").FilterField(zap.Int("order", 42)).AssertLogged(t, "slow order")
+	logs.FilterFieldKey("user").AssertLogged(t, "payment failed")
+	logs.FilterMessageSnippet("payment").FilterLevel("info").AssertEmpty(t)
+	logs.AssertNotLogged(t, "stopping")
+
+	if entry := logs.FilterLevel("error").All()[0]; entry.ContextMap()["user"] != "alice" {
+		t.Errorf("fields added by With should be captured: %v", entry.ContextMap())
+	}
+	if taken := logs.TakeAll(); len(taken) != 3 || logs.Len() != 0 {
+		t.Errorf("TakeAll should return and clear the entries, got %d left", logs.Len())
+	}
+
+	glogger.SetLogLevel("warn")
+	glogger.Info("filtered")
+	logs.AssertEmpty(t)
+}
+
+func TestLogtestAssertionFailures(t *testing.T) {
+	glogger, logs := logtest.New()
+	glogger.Info("hello", zap.String("k", "v"))
+
+	recorder := &failureRecorder{TB: t}
+	if logs.AssertCount(recorder, 2) || logs.AssertLogged(recorder, "bye") || logs.AssertNotLogged(recorder, "hello") {
+		t.Error("failed assertions should return false")
+	}
+	if len(recorder.failures) != 3 {
+		t.Fatalf("expected 3 failures, got %v", recorder.failures)
+	}
+	if want := "\tinfo\t\thello\tmap[k:v]\n"; logs.String() != want {
+		t.Errorf("String() = %q, want %q", logs.String(), want)
+	}
+}
+
+func TestLogtestMiddlewareAndResponse(t *testing.T) {
+	logs := logtest.SetDefault(t)
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), middleware.GetGormConfig("debug"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := gin.New()
+	engine.Use(middleware.GinLogger())
+	engine.GET("/orders", func(c *gin.Context) {
+		var count int64
+		db.WithContext(c.Request.Context()).Raw("SELECT 1").Scan(&count)
+		response.Error(c, http.StatusNotFound, errors.New("order not found"), "not found")
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	logs.AssertCount(t, 3)
+	logs.FilterLogger(middleware.GormLoggerName).FilterFieldKey("sql").AssertLogged(t, middleware.TAG+" Query")
+	logs.FilterLevel("error").AssertLogged(t, response.TagReturn+"/orders")
+	logs.FilterLogger(middleware.HTTPLoggerName).FilterField(zap.Int("status", http.StatusOK)).AssertLogged(t, middleware.TagDefault+"/orders")
+
+	// 同一请求的全部日志带有同一个追踪ID
+	traceID, _ := logs.All()[0].ContextMap()["traceId"].(string)
+	if traceID == "" {
+		t.Fatal("entries should carry the request trace ID")
+	}
+	logs.FilterField(zap.String("traceId", traceID)).AssertCount(t, 3)
+}
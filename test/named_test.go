@@ -0,0 +1,64 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.uber.org/zap"
+)
+
+func TestMonophonicNamedLevels(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("gorm=warn,http=info,*=debug"),
+		monophonic.WithWriter(&buf, "", logger.EncodingJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gorm := glogger.Named("gorm")
+	query := gorm.Named("query").With(zap.String("table", "users"))
+	http := glogger.Named("http")
+
+	glogger.Debug("root debug")
+	gorm.Info("gorm info")
+	query.Warn("query warn")
+	http.Debug("http debug")
+	http.Info("http info")
+
+	out := buf.String()
+	for _, want := range []string{"root debug", "query warn", `"logger":"gorm.query"`, `"table":"users"`, "http info"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q: %q", want, out)
+		}
+	}
+	for _, unwanted := range []string{"gorm info", "http debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output should not contain %q: %q", unwanted, out)
+		}
+	}
+
+	gorm.SetLogLevel("debug")
+	if got := query.GetLogLevel(); got != "debug" {
+		t.Errorf("gorm.query level = %q, want debug inherited from gorm", got)
+	}
+	if got := http.GetLogLevel(); got != "info" {
+		t.Errorf("http level = %q, want info", got)
+	}
+
+	glogger.SetLogLevel("*=error")
+	if got := gorm.GetLogLevel(); got != "error" {
+		t.Errorf("gorm level = %q, want error after replacing the spec", got)
+	}
+}
+
+func TestMonophonicInvalidLevelSpec(t *testing.T) {
+	for _, spec := range []string{"gorm=loud", "=info", "gorm=warn,http"} {
+		if _, err := monophonic.NewWithOptions(monophonic.WithLevel(spec)); err == nil {
+			t.Errorf("expected an error for level %q", spec)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+)
+
+func TestMonophonicNewWithOptions(t *testing.T) {
+	var console, jsonOut bytes.Buffer
+
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("debug"),
+		monophonic.WithWriter(&console, "", logger.EncodingConsole),
+		monophonic.WithWriter(&jsonOut, "info", logger.EncodingJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glogger.Debug("debug message")
+	glogger.Info("info message")
+
+	if !strings.Contains(console.String(), "debug message") || !strings.Contains(console.String(), "info message") {
+		t.Errorf("console sink should receive debug and info entries, got %q", console.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(jsonOut.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("json sink should only receive the info entry, got %d lines", len(lines))
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("json sink wrote invalid json: %v", err)
+	}
+	if entry["msg"] != "info message" {
+		t.Errorf("unexpected json entry: %v", entry)
+	}
+}
+
+func TestMonophonicNewWithInvalidOptions(t *testing.T) {
+	if _, err := monophonic.NewWithOptions(monophonic.WithWriter(nil, "", logger.EncodingConsole)); err == nil {
+		t.Error("expected an error for a writer sink without writer")
+	}
+	if _, err := monophonic.NewWithOptions(monophonic.WithStdout("", "xml")); err == nil {
+		t.Error("expected an error for an unknown encoding")
+	}
+	if _, err := monophonic.NewWithOptions(monophonic.WithLevel("verbose")); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if _, err := monophonic.NewWithOptions(monophonic.WithLevel("gorm=verbose,*=debug")); err == nil {
+		t.Error("expected an error for an unknown module level")
+	}
+	if _, err := monophonic.NewWithOptions(monophonic.WithStdout("verbose", logger.EncodingConsole)); err == nil {
+		t.Error("expected an error for an unknown sink level")
+	}
+	if _, err := logger.NewGLogger(logger.Config{Level: "verbose"}); err == nil {
+		t.Error("NewGLogger should reject unknown levels")
+	}
+}
+
+func TestMonophonicNewFallsBackToInfo(t *testing.T) {
+	// 与 GetLogLevel 一致，New 对无法识别的级别使用 info 而不是 panic
+	for _, level := range []string{"verbose", "gorm=verbose"} {
+		if got := monophonic.New(level, "").GetLogLevel(); got != "info" {
+			t.Errorf("New(%q).GetLogLevel() = %q, want info", level, got)
+		}
+	}
+	if got := monophonic.New("gorm=warn,*=debug", "").Named("gorm").GetLogLevel(); got != "warn" {
+		t.Errorf("module level spec ignored, got %q", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("New should panic on invalid extra options")
+		}
+	}()
+	monophonic.New("info", "", monophonic.WithStdout("", "xml"))
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/logtest"
+	"github.com/uniharmonic/monophonic/middleware"
+	otellog "go.opentelemetry.io/otel/log"
+	otellogtest "go.opentelemetry.io/otel/log/logtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTracer 创建将 span 记录在内存中的 Tracer。
+func newTracer(t *testing.T) (trace.Tracer, *tracetest.SpanRecorder) {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider.Tracer("monophonic-test"), spans
+}
+
+// assertSpanFields 检查日志字段与 span 一致。
+func assertSpanFields(t *testing.T, entry logtest.Entry, sc trace.SpanContext) {
+	t.Helper()
+	fields := entry.ContextMap()
+	if fields[logger.OTelTraceIDKey] != sc.TraceID().String() || fields[logger.OTelSpanIDKey] != sc.SpanID().String() ||
+		fields[logger.OTelTraceFlagsKey] != "01" {
+		t.Errorf("%q should be correlated with span %s/%s: %v", entry.Message, sc.TraceID(), sc.SpanID(), fields)
+	}
+}
+
+func TestMonophonicOTelCorrelation(t *testing.T) {
+	logs := logtest.SetDefault(t)
+	tracer, _ := newTracer(t)
+
+	ctx, span := tracer.Start(context.Background(), "job")
+	ctx = logger.WithTraceID(ctx, "job-1")
+	monophonic.Default().InfoCtx(ctx, "direct")
+	monophonic.Default().WithContext(ctx).Info("bound")
+	span.End()
+	monophonic.Default().InfoCtx(context.Background(), "no span")
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", logs.Messages())
+	}
+	assertSpanFields(t, entries[0], span.SpanContext())
+	assertSpanFields(t, entries[1], span.SpanContext())
+	if entries[0].Context[0].Key != logger.TraceIDKey || entries[0].Context[1].Key != logger.OTelTraceIDKey {
+		t.Errorf("traceId should come first, followed by the span fields: %v", entries[0].Context)
+	}
+	if _, ok := entries[2].ContextMap()[logger.OTelTraceIDKey]; ok {
+		t.Error("entries without a span should not carry span fields")
+	}
+}
+
+func TestMonophonicOTelMiddlewareCorrelation(t *testing.T) {
+	logs := logtest.SetDefault(t)
+	tracer, spans := newTracer(t)
+	db, err := gorm.Open(sqlite.Open("file::memory:"), middleware.GetGormConfig("debug"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		ctx, span := tracer.Start(c.Request.Context(), c.FullPath())
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}, middleware.RequestID(), middleware.GinLogger())
+	engine.GET("/users", func(c *gin.Context) {
+		var n int
+		db.WithContext(c.Request.Context()).Raw("SELECT 1").Scan(&n)
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(ended))
+	}
+	for _, name := range []string{middleware.HTTPLoggerName, "gorm"} {
+		named := logs.FilterLogger(name)
+		if named.AssertCount(t, 1) {
+			assertSpanFields(t, named.All()[0], ended[0].SpanContext())
+		}
+	}
+}
+
+func TestMonophonicOTelLogsBridge(t *testing.T) {
+	recorder := otellogtest.NewRecorder()
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("info"),
+		monophonic.WithOTel(recorder, ""),
+		monophonic.WithRedact(logger.DefaultRedactConfig()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer, _ := newTracer(t)
+	ctx, span := tracer.Start(context.Background(), "checkout")
+	defer span.End()
+
+	glogger.With(zap.String("service", "orders")).InfoCtx(ctx, "paid", zap.Int("amount", 42), zap.String("password", "hunter2"))
+	glogger.Named("gorm").WarnCtx(context.Background(), "slow sql")
+	glogger.Debug("filtered")
+
+	records := map[string][]otellogtest.EmittedRecord{}
+	for _, scope := range recorder.Result() {
+		records[scope.Name] = append(records[scope.Name], scope.Records...)
+	}
+	root := records[logger.OTelScopeName]
+	if len(root) != 1 {
+		t.Fatalf("expected 1 record in the root scope, got %v", records)
+	}
+	record := root[0]
+	if record.Body().AsString() != "paid" || record.Severity() != otellog.SeverityInfo || record.SeverityText() != "info" {
+		t.Errorf("unexpected record: %v %v %q", record.Body(), record.Severity(), record.SeverityText())
+	}
+	if sc := trace.SpanContextFromContext(record.Context()); sc.TraceID() != span.SpanContext().TraceID() || sc.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("record should be emitted with the span context, got %v", sc)
+	}
+	attrs := map[string]otellog.Value{}
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	if attrs["service"].AsString() != "orders" || attrs["amount"].AsInt64() != 42 || attrs["code.lineno"].AsInt64() == 0 {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+	if attrs["password"].AsString() == "hunter2" {
+		t.Error("the redaction rules should apply to the otel sink")
+	}
+	if _, ok := attrs[logger.OTelTraceIDKey]; ok {
+		t.Error("span fields should become the record's span context instead of attributes")
+	}
+
+	gormRecords := records["gorm"]
+	if len(gormRecords) != 1 || gormRecords[0].Severity() != otellog.SeverityWarn {
+		t.Fatalf("named loggers should use their own scope: %v", records)
+	}
+	if trace.SpanContextFromContext(gormRecords[0].Context()).IsValid() {
+		t.Error("records without a span should not carry a span context")
+	}
+}
+
+func TestMonophonicOTelSinkConfig(t *testing.T) {
+	cfg, err := logger.FileConfig{Outputs: []logger.OutputFileConfig{{Type: logger.SinkOTel, Level: "warn"}}}.Config()
+	if err != nil || cfg.Sinks[0].Type != logger.SinkOTel {
+		t.Fatalf("otel outputs should be configurable from a file: %+v, %v", cfg, err)
+	}
+	async := logger.DefaultAsyncConfig()
+	err = logger.Config{Sinks: []logger.SinkConfig{{Type: logger.SinkOTel, Async: &async}}}.Validate()
+	if err == nil {
+		t.Error("async otel sinks should be rejected")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !race
+
+package test
+
+// raceEnabled 表示测试是否以 -race 运行，见 race_enabled_test.go。
+const raceEnabled = false
@@ -0,0 +1,6 @@
+//go:build race
+
+package test
+
+// raceEnabled 表示测试是否以 -race 运行：竞态检测会随机丢弃 sync.Pool 中的对象，内存分配次数不再稳定。
+const raceEnabled = true
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestMonophonicRedactKeys(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithRedact(logger.DefaultRedactConfig()))
+
+	glogger.With(zap.String("access_token", "with-secret")).Info("login",
+		zap.String("password", "p@ss"),
+		zap.Any("headers", map[string]string{"Authorization": "Bearer abc", "Accept": "*/*"}),
+		zap.String("query", "user=alice&token=abc123&page=1"),
+		zap.String("body", `{"user":{"name":"alice","pwd":"123"}}`),
+	)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry["access_token"] != "***" || entry["password"] != "***" {
+		t.Errorf("sensitive fields not masked: %v", entry)
+	}
+	headers, _ := entry["headers"].(map[string]any)
+	if headers["Authorization"] != "***" || headers["Accept"] != "*/*" {
+		t.Errorf("nested key not masked: %v", headers)
+	}
+	if entry["query"] != "user=alice&token=***&page=1" {
+		t.Errorf("query = %v", entry["query"])
+	}
+	if entry["body"] != `{"user":{"name":"alice","pwd":"***"}}` {
+		t.Errorf("body = %v", entry["body"])
+	}
+}
+
+func TestMonophonicRedactPatterns(t *testing.T) {
+	var second bytes.Buffer
+	glogger, first := newBufferLogger(t,
+		monophonic.WithRedact(logger.DefaultRedactConfig()),
+		monophonic.WithWriter(&second, "", logger.EncodingJSON),
+	)
+
+	glogger.Warn("contact alice@example.com",
+		zap.String("sql", "SELECT * FROM users WHERE phone = '13812345678'"),
+		zap.String("card", "pay with 6222 0212 3456 7890 128"),
+		zap.String("id", "110101199003071234"),
+		zap.Error(errors.New("user bob@example.com not found")),
+	)
+
+	for _, buf := range []*bytes.Buffer{first, &second} {
+		out := buf.String()
+		for _, secret := range []string{"alice@example.com", "13812345678", "6222 0212", "110101199003071234", "bob@example.com"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%q leaked into a sink: %s", secret, out)
+			}
+		}
+		if !strings.Contains(out, "SELECT * FROM users WHERE phone = '***'") {
+			t.Errorf("free text should keep its non-sensitive parts: %s", out)
+		}
+	}
+}
+
+func TestMonophonicRedactJSONPaths(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithRedact(logger.RedactConfig{
+		JSONPaths: []string{"body.cards[*].number", "$.profile.name"},
+		Mask:      "[hidden]",
+	}))
+
+	glogger.Info("paths",
+		zap.String("body", `{"cards":[{"number":"1234","bank":"abc"},{"number":"5678"}]}`),
+		zap.Any("profile", struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+		}{"alice", 30}),
+	)
+
+	entry := decodeLines(t, buf)[0]
+	if entry["body"] != `{"cards":[{"bank":"abc","number":"[hidden]"},{"number":"[hidden]"}]}` {
+		t.Errorf("body = %v", entry["body"])
+	}
+	profile, _ := entry["profile"].(map[string]any)
+	if profile["name"] != "[hidden]" || profile["age"] != float64(30) {
+		t.Errorf("profile = %v", profile)
+	}
+}
+
+func TestMonophonicRedactGinRequest(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithRedact(logger.DefaultRedactConfig()))
+	t.Cleanup(monophonic.SetDefault(glogger))
+
+	engine := gin.New()
+	engine.Use(middleware.GinLogger())
+	engine.POST("/login", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	form := url.Values{"username": {"alice"}, "password": {"p@ss"}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if query := entries[0]["query"]; query != `{"password":"***","username":["alice"]}` {
+		t.Errorf("query = %v", query)
+	}
+}
+
+func TestMonophonicRedactGormSQL(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithRedact(logger.DefaultRedactConfig()))
+	t.Cleanup(monophonic.SetDefault(glogger))
+
+	// 以 DryRun 生成 MySQL 方言的 SQL，无需连接数据库
+	cfg := middleware.GetGormConfig("debug")
+	cfg.DryRun = true
+	cfg.DisableAutomaticPing = true
+	cfg.SkipDefaultTransaction = true
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/app", SkipInitializeWithVersion: true}), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("users").Where("id = ?", 1).Updates(map[string]any{"password": "hunter2", "token": "abc"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("UPDATE `users` SET `password`=\"hunter2\",`token`='abc'")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %s", len(entries), buf.String())
+	}
+	for _, entry := range entries {
+		sql, _ := entry["sql"].(string)
+		if !strings.Contains(sql, "`password`=") || strings.Contains(sql, "hunter2") || strings.Contains(sql, "abc") {
+			t.Errorf("backtick quoted columns should be masked: %s", sql)
+		}
+	}
+}
+
+func TestMonophonicRedactKeepsIDs(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithRedact(logger.DefaultRedactConfig()))
+	snowflake, err := logger.NewSnowflakeGenerator(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traceID := snowflake.Generate()
+
+	ctx := logger.WithTraceID(context.Background(), traceID)
+	glogger.InfoCtx(ctx, "order paid",
+		zap.String("createdAt", "1700000000000"),
+		zap.String("orderNo", "4000 0000 0000 0001"),
+		zap.String("card", "4111 1111 1111 1111"),
+	)
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry[logger.TraceIDKey] != traceID {
+		t.Errorf("snowflake trace ID should survive the default rules, got %v", entry[logger.TraceIDKey])
+	}
+	if entry["createdAt"] != "1700000000000" || entry["orderNo"] != "4000 0000 0000 0001" {
+		t.Errorf("numbers failing the Luhn check should not be masked: %v", entry)
+	}
+	if entry["card"] != "***" {
+		t.Errorf("card numbers should be masked, got %v", entry["card"])
+	}
+}
+
+func TestMonophonicRedactConfig(t *testing.T) {
+	cfg, err := logger.ParseConfig([]byte("redact:\n  defaults: true\n  keys: [id_number]\n  mask: '#'\n"), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Redact == nil || cfg.Redact.Mask != "#" || cfg.Redact.Keys[len(cfg.Redact.Keys)-1] != "id_number" || len(cfg.Redact.Patterns) == 0 {
+		t.Errorf("unexpected redact config: %+v", cfg.Redact)
+	}
+
+	for _, redact := range []logger.RedactConfig{{Patterns: []string{"("}}, {JSONPaths: []string{"a..b"}}} {
+		if _, err := monophonic.NewWithOptions(monophonic.WithRedact(redact)); err == nil {
+			t.Errorf("expected an error for %+v", redact)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+)
+
+func TestMonophonicRotateExternalMoved(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.log")
+	// 每次写入前都检查文件是否被移动或删除
+	file := logger.NewRotateFile(path, logger.RotateConfig{MaxSize: 1, External: true, ExternalCheckInterval: time.Nanosecond})
+	defer file.Close()
+
+	if _, err := file.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟 logrotate 的 create 模式：移走当前文件
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("after move\n")); err != nil {
+		t.Fatal(err)
+	}
+	if moved, _ := os.ReadFile(path + ".1"); string(moved) != "before\n" {
+		t.Errorf("moved file content = %q", moved)
+	}
+	if current, _ := os.ReadFile(path); string(current) != "after move\n" {
+		t.Errorf("the file should be recreated after it was moved, got %q", current)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("after remove\n")); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := os.ReadFile(path); string(current) != "after remove\n" {
+		t.Errorf("the file should be recreated after it was removed, got %q", current)
+	}
+	if backups := rotatedFiles(t, dir); len(backups) != 1 {
+		t.Errorf("external rotation should not create backups, got %v", backups)
+	}
+}
+
+func TestMonophonicRotateExternalCheckInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.log")
+	file := logger.NewRotateFile(path, logger.RotateConfig{External: true, ExternalCheckInterval: time.Hour})
+	defer file.Close()
+
+	if _, err := file.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	// 间隔内不检查文件，日志继续写入被移走的文件，直到 Reopen
+	if _, err := file.Write([]byte("within interval\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the file should not be checked within the interval: %v", err)
+	}
+	if moved, _ := os.ReadFile(path + ".1"); string(moved) != "before\nwithin interval\n" {
+		t.Errorf("moved file content = %q", moved)
+	}
+	if err := file.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("after reopen\n")); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := os.ReadFile(path); string(current) != "after reopen\n" {
+		t.Errorf("current file content = %q", current)
+	}
+}
+
+func TestMonophonicReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("info"),
+		monophonic.WithFile(path, "", logger.EncodingJSON, logger.RotateConfig{External: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer glogger.Close()
+
+	glogger.Info("first")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := glogger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Reopen should create the file: %v", err)
+	}
+	glogger.Info("second")
+	_ = glogger.Sync()
+
+	moved, _ := os.ReadFile(path + ".1")
+	current, _ := os.ReadFile(path)
+	if !strings.Contains(string(moved), "first") || strings.Contains(string(moved), "second") {
+		t.Errorf("moved file content = %q", moved)
+	}
+	if !strings.Contains(string(current), "second") || strings.Contains(string(current), "first") {
+		t.Errorf("reopened file content = %q", current)
+	}
+}
+
+func TestMonophonicRotateExternalConfig(t *testing.T) {
+	t.Setenv(logger.EnvOutputs, "file")
+	t.Setenv(logger.EnvFile, "tmp/run.log")
+	t.Setenv(logger.EnvRotateExternal, "true")
+	cfg, err := logger.ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Sinks[0].Rotate.External {
+		t.Errorf("%s should enable external rotation: %+v", logger.EnvRotateExternal, cfg.Sinks[0].Rotate)
+	}
+
+	t.Setenv(logger.EnvRotateExternal, "sometimes")
+	if _, err := logger.ConfigFromEnv(); err == nil {
+		t.Error("invalid booleans should be rejected")
+	}
+}
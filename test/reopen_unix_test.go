@@ -0,0 +1,45 @@
+//go:build !windows
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+)
+
+func TestMonophonicReopenOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("info"),
+		monophonic.WithFile(path, "", logger.EncodingJSON, logger.RotateConfig{External: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer glogger.Close()
+
+	glogger.Info("first")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("SIGHUP should reopen the log file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
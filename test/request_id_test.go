@@ -0,0 +1,121 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/logtest"
+	"github.com/uniharmonic/monophonic/middleware"
+	"github.com/uniharmonic/monophonic/response"
+	"go.uber.org/zap"
+)
+
+// serveRequestID 依次注册 middlewares 与返回 response.OK 的处理函数，返回响应头中的请求ID与响应体中的 requestId。
+func serveRequestID(t *testing.T, header http.Header, middlewares ...gin.HandlerFunc) (string, string) {
+	t.Helper()
+	engine := gin.New()
+	engine.Use(middlewares...)
+	engine.GET("/ping", func(c *gin.Context) {
+		response.OK(c, nil, "pong")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var body struct {
+		RequestID string `json:"requestId"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return w.Header().Get(middleware.HeaderRequestID), body.RequestID
+}
+
+func TestMonophonicRequestIDPropagation(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	for _, tc := range []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{"x-request-id", http.Header{"X-Request-Id": {"upstream-42"}}, "upstream-42"},
+		{"traceparent", http.Header{"Traceparent": {traceparent}}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"x-request-id wins", http.Header{"X-Request-Id": {"upstream-42"}, "Traceparent": {traceparent}}, "upstream-42"},
+		{"invalid", http.Header{"X-Request-Id": {"bad\nid"}, "Traceparent": {"00-0000"}}, ""},
+		{"missing", nil, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := logtest.SetDefault(t)
+			echoed, returned := serveRequestID(t, tc.header, middleware.RequestID(), middleware.GinLogger())
+			if tc.want != "" && echoed != tc.want {
+				t.Errorf("response header = %q, want %q", echoed, tc.want)
+			}
+			if tc.want == "" && !logger.ValidTraceID(echoed) {
+				t.Errorf("a new ID should be generated, got %q", echoed)
+			}
+			if returned != echoed {
+				t.Errorf("response body requestId %q differs from header %q", returned, echoed)
+			}
+			logs.FilterField(zap.String("traceId", echoed)).AssertCount(t, 2)
+		})
+	}
+}
+
+func TestMonophonicRequestIDWithoutMiddleware(t *testing.T) {
+	logs := logtest.SetDefault(t)
+	echoed, returned := serveRequestID(t, http.Header{"X-Request-Id": {"ignored"}}, middleware.GinLogger())
+	if echoed != "" || returned == "ignored" || returned == "" {
+		t.Errorf("GinLogger alone should neither read nor echo the header: header %q, body %q", echoed, returned)
+	}
+	logs.FilterField(zap.String("traceId", returned)).AssertCount(t, 2)
+
+	// 未注册任何中间件时，同一请求中的多次调用也返回同一个ID
+	engine := gin.New()
+	engine.GET("/ids", func(c *gin.Context) {
+		first := response.RequestID(c)
+		if second := response.RequestID(c); first == "" || first != second || logger.TraceIDFromContext(c.Request.Context()) != first {
+			t.Errorf("RequestID should be stable within a request: %q, %q", first, second)
+		}
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ids", nil))
+}
+
+func TestMonophonicParseTraceparent(t *testing.T) {
+	tp, err := logger.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tp.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tp.ParentID != "00f067aa0ba902b7" || !tp.Sampled() {
+		t.Errorf("unexpected traceparent: %+v", tp)
+	}
+	if tp.String() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("String() = %q", tp.String())
+	}
+	if tp, err := logger.ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil || tp.Sampled() {
+		t.Errorf("future versions with extra fields should be accepted: %+v, %v", tp, err)
+	}
+
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", // 版本 00 不允许额外字段
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",
+		"cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01extra",
+		strings.Repeat("0", 55),
+	} {
+		if _, err := logger.ParseTraceparent(header); err == nil {
+			t.Errorf("ParseTraceparent(%q) should fail", header)
+		}
+	}
+}
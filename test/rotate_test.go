@@ -0,0 +1,233 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/uniharmonic/monophonic/logger"
+)
+
+// rotatedFiles 返回目录中除当前文件外的全部文件名。
+func rotatedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Name() != "run.log" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hookRecorder 记录 RotateHook 收到的路径。
+type hookRecorder struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (h *hookRecorder) hook(path string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.paths = append(h.paths, path)
+}
+
+func TestMonophonicRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	file := logger.NewRotateFile(filepath.Join(dir, "run.log"), logger.RotateConfig{MaxSize: 1, Compression: logger.CompressionNone})
+	chunk := bytes.Repeat([]byte("x"), 600<<10)
+	for i := 0; i < 3; i++ {
+		if _, err := file.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := rotatedFiles(t, dir)
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %v", backups)
+	}
+	for _, name := range backups {
+		if !strings.HasPrefix(name, "run-") || !strings.HasSuffix(name, ".log") {
+			t.Errorf("unexpected backup name %q", name)
+		}
+	}
+}
+
+func TestMonophonicRotateDaily(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.log")
+	if err := os.WriteFile(path, []byte("yesterday\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().AddDate(0, 0, -1)
+	if err := os.Chtimes(path, yesterday, yesterday); err != nil {
+		t.Fatal(err)
+	}
+
+	var hooks hookRecorder
+	file := logger.NewRotateFile(path, logger.RotateConfig{
+		Interval:    logger.RotateDaily,
+		Compression: logger.CompressionZstd,
+		OnRotate:    []logger.RotateHook{hooks.hook},
+	})
+	if _, err := file.Write([]byte("today\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dir, "run-"+yesterday.Format("2006-01-02")+".log.zst")
+	if len(hooks.paths) != 1 || hooks.paths[0] != want {
+		t.Fatalf("hook should receive %s, got %v", want, hooks.paths)
+	}
+	compressed, err := os.Open(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer compressed.Close()
+	decoder, err := zstd.NewReader(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer decoder.Close()
+	if content, _ := io.ReadAll(decoder); string(content) != "yesterday\n" {
+		t.Errorf("backup content = %q", content)
+	}
+	if current, _ := os.ReadFile(path); string(current) != "today\n" {
+		t.Errorf("current file content = %q", current)
+	}
+}
+
+func TestMonophonicRotateWithinPeriod(t *testing.T) {
+	dir := t.TempDir()
+	var hooks hookRecorder
+	file := logger.NewRotateFile(filepath.Join(dir, "run.log"), logger.RotateConfig{
+		Interval: logger.RotateHourly,
+		Compress: true,
+		OnRotate: []logger.RotateHook{hooks.hook},
+	})
+	for _, content := range []string{"first\n", "second\n"} {
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	stamp := "run-" + time.Now().Format("2006-01-02T15")
+	backups := rotatedFiles(t, dir)
+	if len(backups) != 2 || backups[0] != stamp+".1.log.gz" || backups[1] != stamp+".log.gz" {
+		t.Fatalf("backups in the same period should be numbered, got %v", backups)
+	}
+	reader, err := os.Open(filepath.Join(dir, stamp+".1.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := io.ReadAll(gz); string(content) != "second\n" {
+		t.Errorf("the numbered backup should be the newer one, got %q", content)
+	}
+	if len(hooks.paths) != 2 {
+		t.Errorf("hooks should fire once per rotation, got %v", hooks.paths)
+	}
+}
+
+func TestMonophonicRotateRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.log")
+	// 之前留下的旧文件
+	for _, name := range []string{"run-2020-01-01.log", "run-2020-01-02.log.gz", "other-2020-01-01.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("old"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := logger.NewRotateFile(path, logger.RotateConfig{MaxSize: -1, MaxTotalSize: 1, Compression: logger.CompressionNone})
+	chunk := bytes.Repeat([]byte("x"), 400<<10)
+	for i := 0; i < 3; i++ {
+		if _, err := file.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := rotatedFiles(t, dir)
+	var total int64
+	for _, name := range backups {
+		if strings.HasPrefix(name, "run-2020") {
+			t.Errorf("the oldest backups should be removed first, found %s", name)
+		}
+		info, _ := os.Stat(filepath.Join(dir, name))
+		total += info.Size()
+	}
+	if len(backups) != 3 || backups[0] != "other-2020-01-01.log" || total > 1<<20+3 {
+		t.Errorf("backups should be capped at 1MB in total, got %v (%d bytes)", backups, total)
+	}
+
+	file = logger.NewRotateFile(path, logger.RotateConfig{MaxBackups: 1, Compression: logger.CompressionNone})
+	if err := file.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if backups := rotatedFiles(t, dir); len(backups) != 2 {
+		t.Errorf("only 1 backup should be kept, got %v", backups)
+	}
+}
+
+func TestMonophonicRotateConfigFromFile(t *testing.T) {
+	cfg, err := logger.ParseConfig([]byte(`
+outputs:
+  - type: file
+    path: tmp/run.log
+    rotation:
+      interval: hourly
+      compression: zstd
+      max_total_size: 512
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotate := cfg.Sinks[0].Rotate
+	if rotate.Interval != logger.RotateHourly || rotate.Compression != logger.CompressionZstd || rotate.MaxTotalSize != 512 || rotate.MaxSize != 100 {
+		t.Errorf("unexpected rotation: %+v", rotate)
+	}
+
+	for _, rotation := range []string{"interval: weekly", "compression: lz4"} {
+		_, err := logger.ParseConfig([]byte("outputs:\n  - type: file\n    path: tmp/run.log\n    rotation:\n      "+rotation+"\n"), "yaml")
+		if err == nil {
+			t.Errorf("%q should be rejected", rotation)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+	"github.com/uniharmonic/monophonic/response"
+	"go.uber.org/zap"
+)
+
+// routedMessages 返回 JSON 日志中按顺序出现的消息。
+func routedMessages(t *testing.T, data []byte) []string {
+	t.Helper()
+	var messages []string
+	for _, entry := range decodeLines(t, bytes.NewBuffer(data)) {
+		messages = append(messages, entry["msg"].(string))
+	}
+	return messages
+}
+
+func TestMonophonicRoute(t *testing.T) {
+	var run, errs, sql, access bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("debug"),
+		monophonic.WithSink(logger.SinkConfig{Type: logger.SinkWriter, Writer: &run, Encoding: logger.EncodingJSON,
+			Route: &logger.RouteConfig{Loggers: []string{middleware.GormLoggerName}, Tags: []string{middleware.TagDefault}, Exclude: true}}),
+		monophonic.WithSink(logger.SinkConfig{Type: logger.SinkWriter, Writer: &errs, Encoding: logger.EncodingJSON, Level: "error"}),
+		monophonic.WithSink(logger.SinkConfig{Type: logger.SinkWriter, Writer: &sql, Encoding: logger.EncodingJSON,
+			Route: &logger.RouteConfig{Tags: []string{middleware.TAG}}}),
+		monophonic.WithSink(logger.SinkConfig{Type: logger.SinkWriter, Writer: &access, Encoding: logger.EncodingJSON,
+			Route: &logger.RouteConfig{Loggers: []string{middleware.HTTPLoggerName}, Tags: []string{response.TagReturn}}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glogger.Named(middleware.GormLoggerName).Info(middleware.TAG + " Query")
+	glogger.Named(middleware.GormLoggerName).Error(middleware.TAG + " Error")
+	glogger.Named(middleware.HTTPLoggerName).Info(middleware.TagDefault + "/users")
+	glogger.Named(middleware.HTTPClientLoggerName).With(zap.String("peer", "billing")).Info("outgoing")
+	glogger.Info(response.TagReturn + "/users")
+	glogger.Error(middleware.TagRecovery)
+	glogger.Named("gormish").Debug("unrelated")
+	_ = glogger.Sync()
+
+	for name, c := range map[string]struct {
+		buf  *bytes.Buffer
+		want []string
+	}{
+		"run":    {&run, []string{"outgoing", "[Return]/users", "[Recovery from panic]", "unrelated"}},
+		"error":  {&errs, []string{"[GORM] Error", "[Recovery from panic]"}},
+		"sql":    {&sql, []string{"[GORM] Query", "[GORM] Error"}},
+		"access": {&access, []string{"[Receive]/users", "outgoing", "[Return]/users"}},
+	} {
+		if got := routedMessages(t, c.buf.Bytes()); strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("%s log = %q, want %q", name, got, c.want)
+		}
+	}
+
+	_, err = monophonic.NewWithOptions(monophonic.WithSink(logger.SinkConfig{Type: logger.SinkStdout, Route: &logger.RouteConfig{Exclude: true}}))
+	if err == nil {
+		t.Error("routes without loggers or tags should be rejected")
+	}
+}
+
+func TestMonophonicRouteConfigFromFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := logger.ParseConfig([]byte(`
+level: info
+outputs:
+  - type: file
+    path: `+filepath.Join(dir, "run.log")+`
+    route:
+      loggers: [gorm]
+      exclude: true
+  - type: file
+    path: `+filepath.Join(dir, "error.log")+`
+    level: error
+    rotation:
+      max_backups: 90
+  - type: file
+    path: `+filepath.Join(dir, "sql.log")+`
+    rotation:
+      interval: daily
+    route:
+      tags: ["[GORM]"]
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route := cfg.Sinks[0].Route; route == nil || !route.Exclude || route.Loggers[0] != "gorm" {
+		t.Fatalf("unexpected route: %+v", route)
+	}
+	if cfg.Sinks[1].Rotate.MaxBackups != 90 || cfg.Sinks[2].Rotate.Interval != logger.RotateDaily {
+		t.Errorf("each output should keep its own rotation: %+v %+v", cfg.Sinks[1].Rotate, cfg.Sinks[2].Rotate)
+	}
+
+	glogger, err := logger.NewGLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	glogger.Named("gorm").Warn("[GORM] Slow Log")
+	glogger.Error("failed")
+	if err := glogger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"run.log": "failed", "error.log": "failed", "sql.log": "[GORM] Slow Log"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := routedMessages(t, data); len(got) != 1 || got[0] != want {
+			t.Errorf("%s = %q, want [%q]", name, got, want)
+		}
+	}
+
+	_, err = logger.ParseConfig([]byte("outputs:\n  - type: stdout\n    route: {}\n"), "yaml")
+	if err == nil {
+		t.Error("empty routes should be rejected")
+	}
+}
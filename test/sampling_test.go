@@ -0,0 +1,124 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+)
+
+func TestMonophonicSamplingFirstThenEvery(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithSampling(logger.SamplingConfig{
+		Interval:        time.Hour,
+		First:           3,
+		Thereafter:      5,
+		SummaryInterval: time.Hour,
+	}))
+
+	for i := 0; i < 23; i++ {
+		glogger.Error("hot path failed")
+	}
+	glogger.Info("other message")
+	if got := glogger.Suppressed(); got != 16 {
+		t.Errorf("Suppressed() = %d, want 16", got)
+	}
+	if err := glogger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 9 {
+		t.Fatalf("expected 3 + 4 sampled, 1 other and 1 summary entries, got %d", len(entries))
+	}
+	summary := entries[len(entries)-1]
+	if summary["msg"] != "[Sampling] suppressed 16 similar entries" || summary["sampled"] != "hot path failed" || summary["level"] != "error" {
+		t.Errorf("unexpected summary entry: %v", summary)
+	}
+}
+
+func TestMonophonicSamplingRateLimit(t *testing.T) {
+	glogger, buf := newBufferLogger(t, monophonic.WithSampling(logger.SamplingConfig{
+		Rate:            1,
+		Burst:           2,
+		SummaryInterval: time.Hour,
+	}))
+
+	hot, cold := glogger.Named("hot"), glogger.Named("cold")
+	for i := 0; i < 10; i++ {
+		hot.Info(fmt.Sprintf("hot %d", i))
+	}
+	cold.Info("cold 0")
+	if got := glogger.Suppressed(); got != 8 {
+		t.Errorf("Suppressed() = %d, want 8", got)
+	}
+	_ = glogger.Close()
+
+	counts := map[string]int{}
+	for _, entry := range decodeLines(t, buf) {
+		if entry["sampled"] == nil {
+			counts[entry["logger"].(string)]++
+		}
+	}
+	if counts["hot"] != 2 || counts["cold"] != 1 {
+		t.Errorf("token bucket should be kept per logger, got %v", counts)
+	}
+}
+
+func TestMonophonicSamplingOffByDefault(t *testing.T) {
+	glogger, buf := newBufferLogger(t)
+	for i := 0; i < 200; i++ {
+		glogger.Info("repeated")
+	}
+	if entries := decodeLines(t, buf); len(entries) != 200 || glogger.Suppressed() != 0 {
+		t.Errorf("sampling should be off by default, got %d entries", len(entries))
+	}
+}
+
+func TestMonophonicSamplingConfig(t *testing.T) {
+	cfg, err := logger.ParseConfig([]byte("sampling:\n  interval: 2s\n  thereafter: 0\n  rate: 50\n"), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := cfg.Sampling
+	if s == nil || s.Interval != 2*time.Second || s.First != 100 || s.Thereafter != 0 || s.Rate != 50 {
+		t.Errorf("unexpected sampling config: %+v", s)
+	}
+	if _, err := logger.ParseConfig([]byte("sampling:\n  summary_interval: soon\n"), "yaml"); err == nil {
+		t.Error("expected an error for an invalid summary_interval")
+	}
+}
+
+func TestMonophonicSamplingIgnoresUnwrittenEntries(t *testing.T) {
+	var buf, gorm bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("debug"),
+		monophonic.WithSink(logger.SinkConfig{
+			Type: logger.SinkWriter, Writer: &gorm, Encoding: logger.EncodingJSON,
+			Route: &logger.RouteConfig{Loggers: []string{"gorm"}},
+		}),
+		monophonic.WithWriter(&buf, "warn", logger.EncodingJSON),
+		monophonic.WithSampling(logger.SamplingConfig{Rate: 1, Burst: 1, SummaryInterval: time.Hour}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 调试日志被第一个输出的路由与第二个输出的级别排除，不应消耗令牌
+	for i := 0; i < 100; i++ {
+		glogger.Debug("flood")
+	}
+	glogger.Warn("important")
+	if got := glogger.Suppressed(); got != 0 {
+		t.Errorf("Suppressed() = %d, want 0", got)
+	}
+	if !strings.Contains(buf.String(), "important") {
+		t.Errorf("warn entry was suppressed by unwritten debug entries: %q", buf.String())
+	}
+	if gorm.Len() != 0 {
+		t.Errorf("route sink received unrelated entries: %q", gorm.String())
+	}
+}
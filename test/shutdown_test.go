@@ -0,0 +1,126 @@
+package test
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/middleware"
+)
+
+func TestMonophonicCloseFlushesAsyncFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "close.log")
+	glogger, err := monophonic.NewWithOptions(monophonic.WithSink(logger.SinkConfig{
+		Type:     logger.SinkFile,
+		Path:     path,
+		Encoding: logger.EncodingJSON,
+		Async:    &logger.AsyncConfig{FlushInterval: time.Hour},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glogger.Named("child").Info("before close")
+	if err := glogger.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before close") {
+		t.Errorf("Close() did not flush the async queue, file = %q", data)
+	}
+
+	// 关闭后继续记录的日志同步写出，并重新打开文件
+	glogger.Info("after close")
+	if err := glogger.Close(); err != nil {
+		t.Fatalf("second Close() = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "after close") {
+		t.Errorf("entry logged after Close() is missing, file = %q", data)
+	}
+}
+
+func TestMonophonicSyncIgnoresStdout(t *testing.T) {
+	glogger, err := monophonic.NewWithOptions(monophonic.WithStdout("", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := glogger.Sync(); err != nil {
+		t.Errorf("Sync() on stdout = %v", err)
+	}
+}
+
+func TestMonophonicGracefulShutdown(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(monophonic.WithWriter(&buf, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(monophonic.SetDefault(glogger))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	engine := gin.New()
+	engine.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	srv := &http.Server{Addr: addr, Handler: engine}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- middleware.ListenAndServe(srv, time.Second, os.Interrupt)
+	}()
+
+	// 服务可以响应请求时信号处理已经注册，此时发送信号不会终止测试进程
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		res, err := http.Get("http://" + addr + "/ping")
+		if err == nil {
+			_ = res.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot signal own process: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ListenAndServe() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not shut down after the signal")
+	}
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected signal and stopped entries, got %d", len(entries))
+	}
+	if entries[1]["msg"] != middleware.TagShutdown+" server stopped" || entries[1]["duration"] == nil {
+		t.Errorf("shutdown duration not logged: %v", entries[1])
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMonophonicSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("info"),
+		monophonic.WithRedact(logger.DefaultRedactConfig()),
+		monophonic.WithWriter(&buf, "", logger.EncodingJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(logger.NewSlogHandler(glogger)).With("app", "demo").WithGroup("req")
+	ctx := logger.WithTraceID(context.Background(), "trace-slog")
+	log.DebugContext(ctx, "hidden")
+	log.InfoContext(ctx, "handled", "id", 7, slog.Group("user", "name", "alice", "password", "p@ss"))
+	log.WithGroup("empty").Warn("no attrs")
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	entry := entries[0]
+	req, _ := entry["req"].(map[string]any)
+	user, _ := req["user"].(map[string]any)
+	if entry[logger.TraceIDKey] != "trace-slog" || entry["app"] != "demo" || req["id"] != float64(7) {
+		t.Errorf("attrs not mapped: %v", entry)
+	}
+	if user["name"] != "alice" || user["password"] != "***" {
+		t.Errorf("nested group not mapped or not redacted: %v", user)
+	}
+	if entry["caller"] == nil {
+		t.Errorf("caller missing: %v", entry)
+	}
+	if _, ok := entries[1]["req"]; ok {
+		t.Errorf("groups without attrs should be omitted: %v", entries[1])
+	}
+}
+
+func TestMonophonicSlogHandlerModuleLevel(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithLevel("lib=error,*=debug"),
+		monophonic.WithWriter(&buf, "", logger.EncodingJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(logger.NewSlogHandler(glogger.Named("lib").(*logger.GLogger)))
+	log.Info("dropped by module level")
+	log.Error("kept")
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 1 || entries[0]["logger"] != "lib" || entries[0]["msg"] != "kept" {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+}
+
+func TestMonophonicGLoggerFromSlog(t *testing.T) {
+	var buf bytes.Buffer
+	glogger := logger.NewGLoggerFromSlog(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	child := glogger.Named("svc").With(zap.String("a", "b"), zap.Namespace("ns"), zap.Int("n", 1))
+	child.Debug("hidden")
+	child.Info("hello", zap.Int("m", 2))
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	ns, _ := entry["ns"].(map[string]any)
+	if entry["msg"] != "hello" || entry["level"] != "INFO" || entry["logger"] != "svc" || entry["a"] != "b" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if ns["n"] != float64(1) || ns["m"] != float64(2) {
+		t.Errorf("namespace should map to a group: %v", entry)
+	}
+
+	glogger.SetLogLevel("warn")
+	glogger.Info("filtered by SetLogLevel")
+	if len(decodeLines(t, &buf)) != 1 {
+		t.Error("SetLogLevel should still apply on top of the handler")
+	}
+}
+
+func TestMonophonicSlogHandlerFollowsReload(t *testing.T) {
+	glogger, first := newBufferLogger(t, monophonic.WithLevel("info"))
+	log := slog.New(logger.NewSlogHandler(glogger))
+
+	var second bytes.Buffer
+	err := glogger.Reload(logger.Config{Level: "info", Sinks: []logger.SinkConfig{{Type: logger.SinkWriter, Writer: &second, Encoding: logger.EncodingJSON}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("after reload")
+	if first.Len() != 0 || len(decodeLines(t, &second)) != 1 {
+		t.Errorf("slog handler should write to the reloaded sinks, old=%q new=%q", first.String(), second.String())
+	}
+}
+
+func TestMonophonicSlogHandlerFollowsSetLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	glogger := &logger.GLogger{ZapLogger: zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel))}
+	log := slog.New(logger.NewSlogHandler(glogger))
+
+	glogger.SetLogLevel("warn")
+	log.Info("filtered by SetLogLevel")
+	if buf.Len() != 0 {
+		t.Errorf("slog handler should apply SetLogLevel, got %q", buf.String())
+	}
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/logtest"
+	"github.com/uniharmonic/monophonic/middleware"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMonophonicSpanNesting(t *testing.T) {
+	logs := logtest.SetDefault(t)
+
+	ctx := logger.WithTraceID(context.Background(), "trace-1")
+	ctx, endRoot := monophonic.Start(ctx, "root")
+	childCtx, endChild := monophonic.Start(ctx, "child")
+	_, endLeaf := monophonic.Start(childCtx, "leaf")
+	endLeaf(zap.Int("items", 3))
+	endLine := line()
+	endChild()
+	endChild()
+	endRoot()
+
+	spans := logger.SpansFromContext(ctx)
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	root, child, leaf := spans[0], spans[1], spans[2]
+	if root.ParentID != "" || child.ParentID != root.SpanID || leaf.ParentID != child.SpanID {
+		t.Errorf("spans should be nested: %+v", spans)
+	}
+	for _, span := range spans {
+		if span.TraceID != "trace-1" || span.End.IsZero() {
+			t.Errorf("span %q should be ended under the request trace ID: %+v", span.Name, span)
+		}
+	}
+	if root.Start.After(child.Start) || root.End.Before(child.End) {
+		t.Error("the root span should enclose its children")
+	}
+
+	spanLogs := logs.FilterMessageSnippet(logger.TagSpan)
+	if spanLogs.AssertCount(t, 3) {
+		entries := spanLogs.All()
+		fields := entries[0].ContextMap()
+		if entries[0].Message != logger.TagSpan+"leaf" || fields["traceId"] != "trace-1" || fields["spanId"] != leaf.SpanID ||
+			fields["parentSpanId"] != child.SpanID || fields["items"] != int64(3) {
+			t.Errorf("unexpected span log: %s %v", entries[0].Message, fields)
+		}
+		if entries[0].Level != zap.DebugLevel {
+			t.Errorf("span logs should use the debug level, got %s", entries[0].Level)
+		}
+		if want := fmt.Sprintf("/test/span_test.go:%d", endLine); !strings.HasSuffix(entries[1].Caller.String(), want) {
+			t.Errorf("span log caller = %q, want %s", entries[1].Caller.String(), want)
+		}
+		if _, ok := entries[2].ContextMap()["parentSpanId"]; ok {
+			t.Error("the root span should not log a parent span ID")
+		}
+	}
+
+	if span := logger.RecordSpan(context.Background(), "orphan", time.Now(), time.Now()); span.SpanID != "" {
+		t.Error("spans outside a trace should not be recorded")
+	}
+}
+
+func TestMonophonicSpanMiddleware(t *testing.T) {
+	logs := logtest.SetDefault(t)
+	db, err := gorm.Open(sqlite.Open("file::memory:"), middleware.GetGormConfig("debug"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spans []logger.Span
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Next()
+		spans = logger.SpansFromContext(c.Request.Context())
+	}, middleware.RequestID(), middleware.GinLogger())
+	engine.GET("/users", func(c *gin.Context) {
+		ctx, end := monophonic.Start(c.Request.Context(), "load users")
+		defer end()
+		var n int
+		db.WithContext(ctx).Raw("SELECT 1").Scan(&n)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(middleware.HeaderRequestID, "req-7")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %+v", spans)
+	}
+	request, load, query := spans[0], spans[1], spans[2]
+	if request.Name != "GET /users" || load.ParentID != request.SpanID || query.Name != "gorm.select" || query.ParentID != load.SpanID {
+		t.Errorf("unexpected spans: %+v", spans)
+	}
+	for _, span := range spans {
+		if span.TraceID != "req-7" {
+			t.Errorf("span %q should use the request ID, got %q", span.Name, span.TraceID)
+		}
+	}
+
+	for name, want := range map[string]string{middleware.HTTPLoggerName: request.SpanID, middleware.GormLoggerName: query.SpanID} {
+		named := logs.FilterLogger(name)
+		if named.AssertCount(t, 1) && named.All()[0].ContextMap()["spanId"] != want {
+			t.Errorf("%s log should reference span %s: %v", name, want, named.All()[0].ContextMap())
+		}
+	}
+}
+
+func TestMonophonicChromeTrace(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return base.Add(time.Duration(ms) * time.Millisecond) }
+	spans := []logger.Span{
+		{TraceID: "t", SpanID: "a", Name: "request", Start: at(0), End: at(100)},
+		{TraceID: "t", SpanID: "b", ParentID: "a", Name: "first", Start: at(10), End: at(60), Fields: []zap.Field{zap.Int("rows", 2)}},
+		{TraceID: "t", SpanID: "c", ParentID: "a", Name: "parallel", Start: at(20), End: at(80)},
+		{TraceID: "t", SpanID: "d", ParentID: "a", Name: "after", Start: at(70), End: at(90)},
+	}
+
+	var buf bytes.Buffer
+	if err := logger.WriteChromeTrace(&buf, spans); err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents []struct {
+			Name string         `json:"name"`
+			Ph   string         `json:"ph"`
+			Ts   float64        `json:"ts"`
+			Dur  float64        `json:"dur"`
+			Tid  int            `json:"tid"`
+			Args map[string]any `json:"args"`
+		} `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(trace.TraceEvents) != 4 {
+		t.Fatalf("expected 4 events, got %s", buf.String())
+	}
+	tids := map[string]int{}
+	for _, event := range trace.TraceEvents {
+		if event.Ph != "X" {
+			t.Errorf("events should be complete events, got %q", event.Ph)
+		}
+		tids[event.Name] = event.Tid
+	}
+	first := trace.TraceEvents[1]
+	if first.Name != "first" || first.Ts != 10000 || first.Dur != 50000 || first.Args["rows"] != float64(2) || first.Args["parentSpanId"] != "a" {
+		t.Errorf("unexpected event: %+v", first)
+	}
+	if tids["request"] != tids["first"] || tids["parallel"] == tids["first"] || tids["after"] != tids["first"] {
+		t.Errorf("overlapping spans that do not nest should use different rows: %v", tids)
+	}
+}
+
+func TestMonophonicChromeTraceRedaction(t *testing.T) {
+	glogger, _ := newBufferLogger(t, monophonic.WithRedact(logger.DefaultRedactConfig()))
+	now := time.Now()
+	spans := []logger.Span{{
+		TraceID: "t", SpanID: "a", Name: "gorm.update", Start: now, End: now.Add(time.Millisecond),
+		Fields: []zap.Field{zap.String("sql", "UPDATE `users` SET `password`='hunter2' WHERE id = 1"), zap.Int64("rows", 1)},
+	}}
+
+	var buf bytes.Buffer
+	if err := glogger.WriteChromeTrace(&buf, spans); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "hunter2") || !strings.Contains(out, "`password`=") {
+		t.Errorf("span fields should be redacted: %s", out)
+	}
+	if spans[0].Fields[0].String != "UPDATE `users` SET `password`='hunter2' WHERE id = 1" {
+		t.Error("WriteChromeTrace must not modify the spans passed in")
+	}
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"go.uber.org/zap"
+)
+
+// countingStringer 记录 String 被调用的次数，用于确认未启用的级别不会格式化参数。
+type countingStringer struct{ calls *int }
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "formatted"
+}
+
+func TestMonophonicSugaredMethods(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(monophonic.WithLevel("info"), monophonic.WithWriter(&buf, "", logger.EncodingJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	glogger.Debugf("value %s", countingStringer{&calls})
+	glogger.Infof("user %s has %d items", "alice", 3)
+	glogger.Warnw("quota exceeded", "user", "alice", "used", 120, zap.String("plan", "free"))
+	var iface logger.LogInterface = glogger.Named("orders")
+	iface.Errorw("payment failed", "order", 42)
+	iface.Errorf("retry %d/%d", 1, 3)
+
+	if calls != 0 {
+		t.Errorf("disabled Debugf should not format its arguments, String called %d times", calls)
+	}
+	entries := decodeLines(t, &buf)
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	if entries[0]["msg"] != "user alice has 3 items" {
+		t.Errorf("Infof msg = %v", entries[0]["msg"])
+	}
+	if w := entries[1]; w["user"] != "alice" || w["used"] != float64(120) || w["plan"] != "free" {
+		t.Errorf("Warnw fields not mapped: %v", w)
+	}
+	if e := entries[2]; e["logger"] != "orders" || e["order"] != float64(42) {
+		t.Errorf("Errorw on a named logger: %v", e)
+	}
+	for _, entry := range entries {
+		caller, _ := entry["caller"].(string)
+		if caller == "" || strings.Contains(caller, "go.uber.org/zap") || strings.Contains(caller, "monophonic/logger/") {
+			t.Errorf("caller should point outside the logger, got %q", caller)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/logtest"
+	"github.com/uniharmonic/monophonic/middleware"
+)
+
+func TestMonophonicTraceIDGenerators(t *testing.T) {
+	snowflake, err := logger.NewSnowflakeGenerator(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		generator logger.TraceIDGenerator
+		format    logger.TraceIDFormat
+		sortable  bool
+	}{
+		{logger.UUIDv4Generator(), logger.TraceIDUUIDv4, false},
+		{logger.UUIDv7Generator(), logger.TraceIDUUIDv7, true},
+		{logger.ULIDGenerator(), logger.TraceIDULID, true},
+		{snowflake, logger.TraceIDSnowflake, true},
+		{logger.W3CTraceIDGenerator(), logger.TraceIDW3C, false},
+	} {
+		start := time.Now().Add(-time.Millisecond)
+		previous := ""
+		// 生成数量超过 Snowflake 单毫秒的序列号上限，确认借用下一毫秒后仍然递增
+		for i := 0; i < 5000; i++ {
+			id := tc.generator.Generate()
+			info, err := logger.ParseTraceID(id)
+			if err != nil || info.Format != tc.format {
+				t.Fatalf("%s: ParseTraceID(%q) = %+v, %v", tc.format, id, info, err)
+			}
+			if id == previous {
+				t.Fatalf("%s: duplicate id %q", tc.format, id)
+			}
+			if tc.sortable {
+				if len(id) == len(previous) && id < previous {
+					t.Fatalf("%s: %q generated after %q", tc.format, id, previous)
+				}
+				if info.Time.Before(start.Truncate(time.Millisecond)) || info.Time.After(time.Now().Add(time.Second)) {
+					t.Fatalf("%s: unexpected time %v in %q", tc.format, info.Time, id)
+				}
+			}
+			previous = id
+		}
+	}
+
+	if _, err := logger.NewSnowflakeGenerator(logger.MaxSnowflakeNode + 1); err == nil {
+		t.Error("out of range snowflake node should be rejected")
+	}
+	if _, err := logger.NewTraceIDGenerator("uuidv9", 0); err == nil {
+		t.Error("unknown format should be rejected")
+	}
+}
+
+func TestMonophonicParseTraceID(t *testing.T) {
+	valid := map[string]logger.TraceIDFormat{
+		"1b4e28ba-2fa1-41d2-883f-0016d3cca427": logger.TraceIDUUIDv4,
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8": logger.TraceIDUUID,
+		"4bf92f3577b34da6a3ce929d0e0e4736":     logger.TraceIDW3C,
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV":           logger.TraceIDULID,
+		"01arz3ndektsv4rrffq69g5fav":           logger.TraceIDULID,
+		"1541815603606036480":                  logger.TraceIDSnowflake,
+		"4194304":                              logger.TraceIDSnowflake, // 纪元后第 1 毫秒
+	}
+	for id, format := range valid {
+		if info, err := logger.ParseTraceID(id); err != nil || info.Format != format {
+			t.Errorf("ParseTraceID(%q) = %+v, %v, want %s", id, info, err, format)
+		}
+	}
+	if info, _ := logger.ParseTraceID("01ARZ3NDEKTSV4RRFFQ69G5FAV"); info.Time.UnixMilli() != 1469922850259 {
+		t.Errorf("unexpected ULID time %v", info.Time)
+	}
+
+	for _, id := range []string{
+		"",
+		"not-a-trace-id",
+		"00000000000000000000000000000000",     // 全0的 W3C trace-id
+		"4BF92F3577B34DA6A3CE929D0E0E4736",     // W3C trace-id 只能使用小写
+		"81ARZ3NDEKTSV4RRFFQ69G5FAV",           // 超过 128 位的 ULID
+		"01ARZ3NDEKTSV4RRFFQ69G5FAU",           // Crockford Base32 不包含 U
+		"1b4e28ba2fa141d2883f0016d3cca42",      // 长度不足
+		"1b4e28ba-2fa1-41d2-883f-0016d3cca42z", // 非法的 UUID
+		"0123",
+		"-1",
+		"1", // 时间戳部分为0，更可能是普通的自增ID
+		"42",
+		"4194303", // 1<<22 - 1
+		"+4194304",
+	} {
+		if logger.ValidTraceID(id) {
+			t.Errorf("ValidTraceID(%q) should be false", id)
+		}
+	}
+}
+
+func TestMonophonicLoggerTraceIDGenerator(t *testing.T) {
+	glogger, err := monophonic.NewWithOptions(monophonic.WithTraceIDGenerator(logger.ULIDGenerator()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := glogger.Named("child")
+	if info, _ := logger.ParseTraceID(child.GenerateTraceId()); info.Format != logger.TraceIDULID {
+		t.Errorf("child loggers should share the generator, got %+v", info)
+	}
+	glogger.SetTraceIDGenerator(logger.W3CTraceIDGenerator())
+	if info, _ := logger.ParseTraceID(child.GenerateTraceId()); info.Format != logger.TraceIDW3C {
+		t.Errorf("SetTraceIDGenerator should apply to existing child loggers, got %+v", info)
+	}
+	glogger.SetTraceIDGenerator(nil)
+	if info, _ := logger.ParseTraceID(glogger.GenerateTraceId()); info.Format != logger.TraceIDUUIDv4 {
+		t.Errorf("nil generator should restore UUIDv4, got %+v", info)
+	}
+
+	cfg, err := logger.ParseConfig([]byte("trace_id:\n  format: snowflake\n  node: 3\n"), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := glogger.Reload(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := logger.ParseTraceID(glogger.GenerateTraceId()); info.Format != logger.TraceIDSnowflake {
+		t.Errorf("Reload should apply the configured generator, got %+v", info)
+	}
+	if _, err := logger.ParseConfig([]byte("trace_id:\n  format: snowflake\n  node: 4096\n"), "yaml"); err == nil || !strings.Contains(err.Error(), "snowflake node") {
+		t.Errorf("invalid node should be rejected, got %v", err)
+	}
+
+	t.Setenv(logger.EnvTraceID, string(logger.TraceIDUUIDv7))
+	t.Setenv(logger.EnvTraceIDNode, "1")
+	envLogger, err := monophonic.NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := logger.ParseTraceID(envLogger.GenerateTraceId()); info.Format != logger.TraceIDUUIDv7 {
+		t.Errorf("%s should select the generator, got %+v", logger.EnvTraceID, info)
+	}
+}
+
+func TestMonophonicGinLoggerTraceIDGenerator(t *testing.T) {
+	logs := logtest.SetDefault(t)
+	monophonic.Default().SetTraceIDGenerator(logger.W3CTraceIDGenerator())
+
+	engine := gin.New()
+	engine.Use(middleware.GinLogger())
+	engine.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	logs.AssertCount(t, 1)
+	traceID, _ := logs.All()[0].ContextMap()["traceId"].(string)
+	if info, err := logger.ParseTraceID(traceID); err != nil || info.Format != logger.TraceIDW3C {
+		t.Errorf("GinLogger should use the configured generator, got %q", traceID)
+	}
+}
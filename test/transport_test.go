@@ -0,0 +1,229 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniharmonic/monophonic"
+	"github.com/uniharmonic/monophonic/logger"
+	"github.com/uniharmonic/monophonic/logtest"
+	"github.com/uniharmonic/monophonic/middleware"
+)
+
+// roundTripFunc 将普通函数适配为 http.RoundTripper。
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newDownstream 启动记录最近一次请求头的下游服务。
+func newDownstream(t *testing.T, status int) (*httptest.Server, *http.Header) {
+	t.Helper()
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func TestMonophonicTransportPropagation(t *testing.T) {
+	logs := logtest.SetDefault(t)
+	downstream, received := newDownstream(t, http.StatusOK)
+	client := &http.Client{Transport: &middleware.Transport{
+		Propagation: middleware.PropagateTraceparent | middleware.PropagateRequestID | middleware.PropagateB3 | middleware.PropagateB3Single,
+	}}
+
+	// GinLogger 开始的内置 span 是本次调用的父 span
+	var requestSpanID string
+	engine := gin.New()
+	engine.Use(middleware.RequestID(), middleware.GinLogger())
+	engine.GET("/orders", func(c *gin.Context) {
+		requestSpanID = logger.SpanIDFromContext(c.Request.Context())
+		req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, downstream.URL+"/stock?sku=42", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+		if req.Header.Get(middleware.HeaderTraceparent) != "" {
+			t.Error("the caller's request must not be modified")
+		}
+	})
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(middleware.HeaderRequestID, "upstream-42")
+	req.Header.Set(middleware.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	tp, err := logger.ParseTraceparent(received.Get(middleware.HeaderTraceparent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tp.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tp.ParentID == "00f067aa0ba902b7" || tp.ParentID == requestSpanID ||
+		requestSpanID == "" || tp.Sampled() {
+		t.Errorf("traceparent should continue the inbound trace with a new span: %+v", tp)
+	}
+	for header, want := range map[string]string{
+		middleware.HeaderRequestID:      "upstream-42",
+		middleware.HeaderB3TraceID:      tp.TraceID,
+		middleware.HeaderB3SpanID:       tp.ParentID,
+		middleware.HeaderB3ParentSpanID: requestSpanID,
+		middleware.HeaderB3Sampled:      "0",
+		middleware.HeaderB3:             tp.TraceID + "-" + tp.ParentID + "-0-" + requestSpanID,
+	} {
+		if got := received.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	clientLogs := logs.FilterLogger(middleware.HTTPClientLoggerName)
+	clientLogs.AssertCount(t, 1)
+	fields := clientLogs.All()[0].ContextMap()
+	if fields["traceId"] != "upstream-42" || fields["url"] != downstream.URL+"/stock" || fields["query"] != "sku=42" ||
+		fields["status"] != int64(http.StatusOK) || fields["spanId"] != tp.ParentID {
+		t.Errorf("unexpected client log fields: %v", fields)
+	}
+}
+
+func TestMonophonicTransportParentSpan(t *testing.T) {
+	logtest.SetDefault(t)
+	tracer, _ := newTracer(t)
+	downstream, received := newDownstream(t, http.StatusOK)
+	client := &http.Client{Transport: &middleware.Transport{Propagation: middleware.PropagateTraceparent | middleware.PropagateB3}}
+	send := func(ctx context.Context) logger.Traceparent {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		tp, err := logger.ParseTraceparent(received.Get(middleware.HeaderTraceparent))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tp
+	}
+
+	// OpenTelemetry 的 span 优先于内置 span 与调用方的 traceparent
+	inbound, _ := logger.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx, end := monophonic.Default().Start(logger.WithTraceparent(context.Background(), inbound), "job")
+	defer end()
+	otelCtx, span := tracer.Start(ctx, "call")
+	defer span.End()
+	sc := span.SpanContext()
+	tp := send(otelCtx)
+	if tp.TraceID != sc.TraceID().String() || tp.ParentID == sc.SpanID().String() || !tp.Sampled() {
+		t.Errorf("traceparent should continue the OpenTelemetry trace with a new span: %+v", tp)
+	}
+	if got := received.Get(middleware.HeaderB3ParentSpanID); got != sc.SpanID().String() {
+		t.Errorf("%s = %q, want the OpenTelemetry span %s", middleware.HeaderB3ParentSpanID, got, sc.SpanID())
+	}
+
+	// 没有 OpenTelemetry 的 span 时，以 GLogger.Start 开始的内置 span 为父 span
+	tp = send(ctx)
+	if tp.TraceID != inbound.TraceID || tp.Sampled() {
+		t.Errorf("traceparent should continue the inbound trace: %+v", tp)
+	}
+	if got, want := received.Get(middleware.HeaderB3ParentSpanID), logger.SpanIDFromContext(ctx); got != want || want == "" {
+		t.Errorf("%s = %q, want the built-in span %q", middleware.HeaderB3ParentSpanID, got, want)
+	}
+}
+
+func TestMonophonicTransportWithoutContext(t *testing.T) {
+	logs := logtest.SetDefault(t)
+	downstream, received := newDownstream(t, http.StatusBadGateway)
+	client := &http.Client{Transport: &middleware.Transport{}}
+
+	req, _ := http.NewRequest(http.MethodPost, downstream.URL, nil)
+	req.Header.Set(middleware.HeaderRequestID, "explicit")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if received.Get(middleware.HeaderRequestID) != "explicit" {
+		t.Errorf("existing headers should be kept, got %q", received.Get(middleware.HeaderRequestID))
+	}
+	if received.Get(middleware.HeaderB3TraceID) != "" {
+		t.Error("B3 headers should not be sent by default")
+	}
+	tp, err := logger.ParseTraceparent(received.Get(middleware.HeaderTraceparent))
+	if err != nil || !tp.Sampled() {
+		t.Fatalf("a sampled traceparent should be generated: %+v, %v", tp, err)
+	}
+	entry := logs.FilterLevel("warn").All()
+	if len(entry) != 1 {
+		t.Fatalf("5xx responses should be logged at warn, got %v", logs.Messages())
+	}
+	if id, _ := entry[0].ContextMap()["traceId"].(string); logger.ToW3CTraceID(id) != tp.TraceID {
+		t.Errorf("logged trace ID %q does not match traceparent %q", id, tp.TraceID)
+	}
+
+	failing := &http.Client{Transport: &middleware.Transport{Base: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}}
+	if _, err := failing.Get("http://unreachable.invalid/"); err == nil {
+		t.Fatal("expected an error")
+	}
+	errorLogs := logs.FilterLevel("error").FilterFieldKey("error")
+	if errorLogs.AssertCount(t, 1) && errorLogs.All()[0].ContextMap()["error"] != "connection refused" {
+		t.Errorf("unexpected error entry: %v", errorLogs)
+	}
+}
+
+func TestMonophonicTransportRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	glogger, err := monophonic.NewWithOptions(
+		monophonic.WithRedact(logger.DefaultRedactConfig()),
+		monophonic.WithWriter(&buf, "", logger.EncodingJSON),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(monophonic.SetDefault(glogger))
+	downstream, _ := newDownstream(t, http.StatusOK)
+
+	client := &http.Client{Transport: &middleware.Transport{}}
+	target := strings.Replace(downstream.URL, "http://", "http://admin:hunter2@", 1) + "/login?access_token=s3cr3t&page=1"
+	resp, err := client.Get(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "s3cr3t") {
+		t.Errorf("credentials should be redacted: %s", out)
+	}
+	if !strings.Contains(out, "page=1") {
+		t.Errorf("non-sensitive query parameters should be kept: %s", out)
+	}
+}
+
+func TestMonophonicToW3CTraceID(t *testing.T) {
+	for id, want := range map[string]string{
+		"4bf92f3577b34da6a3ce929d0e0e4736":     "4bf92f3577b34da6a3ce929d0e0e4736",
+		"1b4e28ba-2fa1-41d2-883f-0016d3cca427": "1b4e28ba2fa141d2883f0016d3cca427",
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV":           "01563e3ab5d3d6764c61efb99302bd5b",
+		"1541815603606036480":                  "00000000000000001565a11f6217a000",
+	} {
+		if got := logger.ToW3CTraceID(id); got != want {
+			t.Errorf("ToW3CTraceID(%q) = %q, want %q", id, got, want)
+		}
+	}
+	custom := logger.ToW3CTraceID("upstream-42")
+	if info, err := logger.ParseTraceID(custom); err != nil || info.Format != logger.TraceIDW3C || custom != logger.ToW3CTraceID("upstream-42") {
+		t.Errorf("custom IDs should map to a stable W3C trace-id, got %q", custom)
+	}
+}
@@ -13,53 +13,56 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"path"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
-func TestmonophonicLogger(t *testing.T) {
-	monophonic.Default.Debug("This is a log test for DEBUG level")
-	monophonic.Default.Info("This is a log test for INFO level")
-	monophonic.Default.Warn("This is a log test for WARN level")
-	monophonic.Default.Error("This is a log test for ERROR level")
+func TestMonophonicLogger(t *testing.T) {
+	monophonic.Default().Debug("This is a log test for DEBUG level")
+	monophonic.Default().Info("This is a log test for INFO level")
+	monophonic.Default().Warn("This is a log test for WARN level")
+	monophonic.Default().Error("This is a log test for ERROR level")
 	// Fatal 会导致程序退出，因此不做测试
 	//monophonic.Default.Fatal("This is a log test for FATAL level")
 }
 
-func TestmonophonicLoggerWithFields(t *testing.T) {
+func TestMonophonicLoggerWithFields(t *testing.T) {
 
 	var fields []zapcore.Field
 
-	monophonic.Default.Debug("This is a log test for DEBUG level", append(fields, zap.String("DEBUG", "debug"))...)
-	monophonic.Default.Info("This is a log test for INFO level", append(fields, zap.String("INFO", "info"))...)
-	monophonic.Default.Warn("This is a log test for WARN level", append(fields, zap.String("WARN", "warn"))...)
-	monophonic.Default.Error("This is a log test for ERROR level", append(fields, zap.String("ERROR", "error"))...)
+	monophonic.Default().Debug("This is a log test for DEBUG level", append(fields, zap.String("DEBUG", "debug"))...)
+	monophonic.Default().Info("This is a log test for INFO level", append(fields, zap.String("INFO", "info"))...)
+	monophonic.Default().Warn("This is a log test for WARN level", append(fields, zap.String("WARN", "warn"))...)
+	monophonic.Default().Error("This is a log test for ERROR level", append(fields, zap.String("ERROR", "error"))...)
 	// Fatal 会导致程序退出，因此不做测试
 	//monophonic.Default.Fatal("This is a log test for FATAL level", append(fields, zap.String("FATAL", "fatal"))...)
 }
 
-func TestmonophonicCustomLogger(t *testing.T) {
+func TestMonophonicCustomLogger(t *testing.T) {
 	logLevels := []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
 
 	// TODO： 后期可以使用级别切换函数来动态切换日志级别
 	for _, logLevel := range logLevels {
 		logfile := path.Join("tmp", logLevel+".log")
 		var Logger logger.LogInterface = monophonic.New(logLevel, logfile)
-		monophonic.Default = Logger.(*logger.GLogger)
+		t.Cleanup(monophonic.SetDefault(Logger.(*logger.GLogger)))
 		fmt.Print(logLevel + "-----------------------------------------------\n")
-		monophonic.Default.Debug("This is a log test for DEBUG level")
-		monophonic.Default.Info("This is a log test for INFO level")
-		monophonic.Default.Warn("This is a log test for WARN level")
-		monophonic.Default.Error("This is a log test for ERROR level")
+		monophonic.Default().Debug("This is a log test for DEBUG level")
+		monophonic.Default().Info("This is a log test for INFO level")
+		monophonic.Default().Warn("This is a log test for WARN level")
+		monophonic.Default().Error("This is a log test for ERROR level")
 		// 注意：Fatal 会结束程序，根据需要决定是否取消注释
 		// monophonic.Default.Fatal("This is a log test for FATAL level")
 	}
 }
 
-func TestmonophonicWithMiddleware(t *testing.T) {
-	monophonic.Default = monophonic.New("debug", "tmp/run.log")
+func TestMonophonicWithMiddleware(t *testing.T) {
+	t.Cleanup(monophonic.SetDefault(monophonic.New("debug", "tmp/run.log")))
 
 	testPath := "/ping"
 
@@ -77,8 +80,8 @@ func TestmonophonicWithMiddleware(t *testing.T) {
 	engine.ServeHTTP(res1, req1)
 }
 
-func TestmonophonicWithMiddlewareAndResponse(t *testing.T) {
-	monophonic.Default = monophonic.New("debug", "tmp/run.log")
+func TestMonophonicWithMiddlewareAndResponse(t *testing.T) {
+	t.Cleanup(monophonic.SetDefault(monophonic.New("debug", "tmp/run.log")))
 
 	testPath := "/ping"
 
@@ -97,26 +100,32 @@ func TestmonophonicWithMiddlewareAndResponse(t *testing.T) {
 	engine.ServeHTTP(res1, req1)
 }
 
-func TestmonophonicWithGORM(t *testing.T) {
-	monophonic.Default = monophonic.New("debug", "tmp/run.log")
+func TestMonophonicWithGORM(t *testing.T) {
+	// 该测试需要本地的 MySQL，未启动时跳过
+	conn, err := net.DialTimeout("tcp", "127.0.0.1:3306", time.Second)
+	if err != nil {
+		t.Skipf("MySQL is not available: %v", err)
+	}
+	_ = conn.Close()
+	t.Cleanup(monophonic.SetDefault(monophonic.New("debug", "tmp/run.log")))
 
 	// 测试报错
 	db, err := gorm.Open(mysql.Open("user:pass@tcp(127.0.0.1:3306)/dbname?charset=utf8mb4&parseTime=True&loc=Local"), middleware.GetGormConfig("debug"))
 
 	// 测试不报错
-	db, err = gorm.Open(sqlite.Open("gorm.db"), middleware.GetGormConfig("error"))
+	db, err = gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "gorm.db")), middleware.GetGormConfig("error"))
 	type Product struct {
 		gorm.Model
 		Code  string
 		Price uint
 	}
 	if err != nil {
-		monophonic.Default.Error(err.Error())
+		monophonic.Default().Error(err.Error())
 	}
 	// 迁移 schema
 	err = db.AutoMigrate(&Product{})
 	if err != nil {
-		monophonic.Default.Fatal(err.Error())
+		monophonic.Default().Fatal(err.Error())
 	}
 
 	// Create
@@ -0,0 +1,49 @@
+package monophonic
+
+import (
+	"io"
+	"log"
+
+	"github.com/uniharmonic/monophonic/logger"
+)
+
+// defaultWriter 在每次写入时取当前的 Default，使 SetDefault 之后的输出同样写入新的实例。
+type defaultWriter struct {
+	name  string
+	level string
+}
+
+// DefaultWriter 返回将每一行写入内容记录为 Default 日志的 io.Writer，见 logger.GLogger.Writer。
+// name 非空时使用 Default().Named(name)，可通过 "name=warn" 等配置单独设置其级别。
+func DefaultWriter(name string, level string) io.Writer {
+	return &defaultWriter{name: name, level: level}
+}
+
+// Write 将 p 按行记录为 Default 的日志。
+func (w *defaultWriter) Write(p []byte) (int, error) {
+	glogger := Default()
+	if w.name != "" {
+		glogger = glogger.Named(w.name).(*logger.GLogger)
+	}
+	return glogger.Writer(w.level).Write(p)
+}
+
+// RedirectStdLog 将标准库 log 包的默认输出重定向到 Default，每行记录为一条 level 级别的日志。
+// 时间等信息由 GLogger 输出，因此会清除 log 包的前缀与标志位。
+// 返回的函数用于恢复重定向前的输出、前缀与标志位。
+//
+// 示例：
+//
+//	restore := monophonic.RedirectStdLog("info")
+//	defer restore()
+func RedirectStdLog(level string) (restore func()) {
+	output, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	log.SetOutput(DefaultWriter("", level))
+	log.SetPrefix("")
+	log.SetFlags(0)
+	return func() {
+		log.SetOutput(output)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}
+}